@@ -0,0 +1,116 @@
+package userconfig
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AutoscaleDefinition describes how the number of instances of a component
+// moves between the minimum and maximum of its scaling settings.
+type AutoscaleDefinition struct {
+	// Target average CPU utilization in percent, e.g. 70.
+	TargetCPU int `json:"target-cpu,omitempty" description:"Target average CPU utilization in percent, e.g. 70"`
+
+	// Target average memory utilization in percent, e.g. 80.
+	TargetMemory int `json:"target-memory,omitempty" description:"Target average memory utilization in percent, e.g. 80"`
+
+	// Name of a custom metric to scale on.
+	Metric string `json:"metric,omitempty" description:"Name of a custom metric to scale on"`
+
+	// Target average value of the custom metric per instance.
+	MetricTarget int `json:"metric-target,omitempty" description:"Target average value of the custom metric per instance"`
+
+	// Time to wait after scaling up before scaling up again, e.g. "1m".
+	ScaleUpCooldown Duration `json:"scale-up-cooldown,omitempty" description:"Time to wait after scaling up before scaling up again, e.g. '1m'"`
+
+	// Time to wait after scaling down before scaling down again, e.g. "5m".
+	ScaleDownCooldown Duration `json:"scale-down-cooldown,omitempty" description:"Time to wait after scaling down before scaling down again, e.g. '5m'"`
+
+	// Maximum number of instances to add at once.
+	ScaleUpStep int `json:"scale-up-step,omitempty" description:"Maximum number of instances to add at once"`
+
+	// Maximum number of instances to remove at once.
+	ScaleDownStep int `json:"scale-down-step,omitempty" description:"Maximum number of instances to remove at once"`
+}
+
+// String returns the marshalled string represantion of its own incarnation.
+// We use it to compare two AutoscaleDefinitions when creating a diff. See
+// diff.go
+func (ad *AutoscaleDefinition) String() string {
+	if ad == nil {
+		return ""
+	}
+
+	raw, err := json.Marshal(ad)
+	if err != nil {
+		panic(fmt.Sprintf("%#v\n", mask(err)))
+	}
+
+	return string(raw)
+}
+
+// Equals returns true if both definitions describe the same policy.
+func (ad *AutoscaleDefinition) Equals(other *AutoscaleDefinition) bool {
+	return ad.String() == other.String()
+}
+
+// validate checks that at least one target is given and that all values
+// are sane. The bounds of the scaling settings are checked by
+// ScaleDefinition.validate.
+func (ad *AutoscaleDefinition) validate() error {
+	if ad.TargetCPU == 0 && ad.TargetMemory == 0 && ad.Metric == "" {
+		return newDiagnostic(InvalidScalingConfigError, "autoscale needs at least one of 'target-cpu', 'target-memory' or 'metric'").withFix("set target-cpu, target-memory or metric")
+	}
+
+	if ad.TargetCPU < 0 || ad.TargetCPU > 100 {
+		return newDiagnostic(InvalidScalingConfigError, "autoscale target-cpu '%d' must be between 1 and 100", ad.TargetCPU).withField("target-cpu").withValue(ad.TargetCPU)
+	}
+
+	if ad.TargetMemory < 0 || ad.TargetMemory > 100 {
+		return newDiagnostic(InvalidScalingConfigError, "autoscale target-memory '%d' must be between 1 and 100", ad.TargetMemory).withField("target-memory").withValue(ad.TargetMemory)
+	}
+
+	if ad.Metric != "" && ad.MetricTarget <= 0 {
+		return newDiagnostic(InvalidScalingConfigError, "autoscale metric '%s' needs a positive 'metric-target'", ad.Metric).withField("metric-target").withValue(ad.MetricTarget)
+	}
+
+	if ad.Metric == "" && ad.MetricTarget != 0 {
+		return newDiagnostic(InvalidScalingConfigError, "autoscale metric-target can only be used with 'metric'").withField("metric-target").withValue(ad.MetricTarget)
+	}
+
+	if !ad.ScaleUpCooldown.IsEmpty() && !ad.ScaleUpCooldown.Valid() {
+		return newDiagnostic(InvalidScalingConfigError, "invalid autoscale scale-up-cooldown '%s'", ad.ScaleUpCooldown).withField("scale-up-cooldown").withValue(ad.ScaleUpCooldown)
+	}
+
+	if !ad.ScaleDownCooldown.IsEmpty() && !ad.ScaleDownCooldown.Valid() {
+		return newDiagnostic(InvalidScalingConfigError, "invalid autoscale scale-down-cooldown '%s'", ad.ScaleDownCooldown).withField("scale-down-cooldown").withValue(ad.ScaleDownCooldown)
+	}
+
+	if ad.ScaleUpStep < 0 {
+		return newDiagnostic(InvalidScalingConfigError, "autoscale scale-up-step '%d' cannot be negative", ad.ScaleUpStep).withField("scale-up-step").withValue(ad.ScaleUpStep)
+	}
+
+	if ad.ScaleDownStep < 0 {
+		return newDiagnostic(InvalidScalingConfigError, "autoscale scale-down-step '%d' cannot be negative", ad.ScaleDownStep).withField("scale-down-step").withValue(ad.ScaleDownStep)
+	}
+
+	return nil
+}
+
+// validateBounds checks the policy against the effective minimum and
+// maximum number of instances.
+func (ad *AutoscaleDefinition) validateBounds(min, max int) error {
+	if min >= max {
+		return newDiagnostic(InvalidScalingConfigError, "autoscale needs scale max '%d' to be greater than scale min '%d'", max, min).withField("max").withValue(max)
+	}
+
+	if ad.ScaleUpStep > max-min {
+		return newDiagnostic(InvalidScalingConfigError, "autoscale scale-up-step '%d' cannot be greater than '%d'", ad.ScaleUpStep, max-min).withField("autoscale.scale-up-step").withValue(ad.ScaleUpStep)
+	}
+
+	if ad.ScaleDownStep > max-min {
+		return newDiagnostic(InvalidScalingConfigError, "autoscale scale-down-step '%d' cannot be greater than '%d'", ad.ScaleDownStep, max-min).withField("autoscale.scale-down-step").withValue(ad.ScaleDownStep)
+	}
+
+	return nil
+}
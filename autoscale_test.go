@@ -0,0 +1,171 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/giantswarm/user-config"
+)
+
+func TestParseAutoscale(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"api": {
+				"image": "registry/namespace/repository:version",
+				"scale": {
+					"min": 2,
+					"max": 10,
+					"autoscale": {
+						"target-cpu": 70,
+						"scale-up-cooldown": "1m",
+						"scale-down-cooldown": "5m",
+						"scale-up-step": 2,
+						"scale-down-step": 1
+					}
+				}
+			}
+		}
+	}`)
+
+	var serviceDef userconfig.ServiceDefinition
+	if err := json.Unmarshal(b, &serviceDef); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	as := serviceDef.Components["api"].Scale.Autoscale
+	if as == nil || as.TargetCPU != 70 || as.ScaleUpStep != 2 || as.ScaleDownCooldown != "5m" {
+		t.Fatalf("invalid autoscale policy: %s", as.String())
+	}
+
+	if err := serviceDef.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+}
+
+func TestAutoscaleValidation(t *testing.T) {
+	tests := []struct {
+		Scale *userconfig.ScaleDefinition
+		Valid bool
+	}{
+		// Valid ones
+		{&userconfig.ScaleDefinition{Autoscale: &userconfig.AutoscaleDefinition{TargetCPU: 70}}, true},
+		{&userconfig.ScaleDefinition{Min: 2, Max: 5, Autoscale: &userconfig.AutoscaleDefinition{TargetMemory: 80, ScaleUpStep: 3}}, true},
+		{&userconfig.ScaleDefinition{Autoscale: &userconfig.AutoscaleDefinition{Metric: "requests-per-second", MetricTarget: 100}}, true},
+
+		// Invalid ones
+		{&userconfig.ScaleDefinition{Autoscale: &userconfig.AutoscaleDefinition{}}, false},
+		{&userconfig.ScaleDefinition{Autoscale: &userconfig.AutoscaleDefinition{TargetCPU: 101}}, false},
+		{&userconfig.ScaleDefinition{Autoscale: &userconfig.AutoscaleDefinition{TargetMemory: -1}}, false},
+		{&userconfig.ScaleDefinition{Autoscale: &userconfig.AutoscaleDefinition{Metric: "requests-per-second"}}, false},
+		{&userconfig.ScaleDefinition{Autoscale: &userconfig.AutoscaleDefinition{TargetCPU: 70, MetricTarget: 100}}, false},
+		{&userconfig.ScaleDefinition{Autoscale: &userconfig.AutoscaleDefinition{TargetCPU: 70, ScaleUpCooldown: "later"}}, false},
+		{&userconfig.ScaleDefinition{Autoscale: &userconfig.AutoscaleDefinition{TargetCPU: 70, ScaleDownStep: -1}}, false},
+		// No room to scale
+		{&userconfig.ScaleDefinition{Min: 3, Max: 3, Autoscale: &userconfig.AutoscaleDefinition{TargetCPU: 70}}, false},
+		// Step too large
+		{&userconfig.ScaleDefinition{Min: 2, Max: 5, Autoscale: &userconfig.AutoscaleDefinition{TargetCPU: 70, ScaleUpStep: 4}}, false},
+	}
+
+	for i, test := range tests {
+		def := ExampleDefinition()
+		def.Components["component/a"].Scale = test.Scale
+
+		valCtx := NewValidationContext()
+		if err := def.SetDefaults(valCtx); err != nil {
+			t.Fatalf("test %d: setting defaults failed: %#v", i, err)
+		}
+
+		err := def.Validate(valCtx)
+		if test.Valid && err != nil {
+			t.Fatalf("test %d: expected definition to be valid, got error: %#v", i, err)
+		}
+		if !test.Valid && !userconfig.IsInvalidScalingConfig(err) {
+			t.Fatalf("test %d: expected error to be InvalidScalingConfigError, got: %#v", i, err)
+		}
+	}
+}
+
+func TestAutoscaleInPods(t *testing.T) {
+	service := testService()
+	service.Components["pod"] = setPod(&userconfig.ComponentDefinition{}, userconfig.PodChildren)
+	service.Components["pod/a"] = testComponent()
+	service.Components["pod/a"].Scale = &userconfig.ScaleDefinition{Autoscale: &userconfig.AutoscaleDefinition{TargetCPU: 70}}
+	service.Components["pod/b"] = testComponent()
+
+	// The explicit policy is shared within the pod
+	valCtx := NewValidationContext()
+	if err := service.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+	if as := service.Components["pod/b"].Scale.Autoscale; as == nil || as.TargetCPU != 70 {
+		t.Fatalf("expected autoscale policy to be shared in pod, got: %s", as.String())
+	}
+	if err := service.Validate(valCtx); err != nil {
+		t.Fatalf("validating service failed: %#v", err)
+	}
+
+	service.Components["pod/b"].Scale.Autoscale = &userconfig.AutoscaleDefinition{TargetCPU: 50}
+	if err := service.Validate(valCtx); !userconfig.IsInvalidScalingConfig(err) {
+		t.Fatalf("expected error to be InvalidScalingConfigError, got: %#v", err)
+	}
+}
+
+func TestAutoscaleDefaults(t *testing.T) {
+	def := ExampleDefinition()
+
+	valCtx := NewValidationContext()
+	valCtx.Autoscale = &userconfig.AutoscaleDefinition{TargetCPU: 80}
+	if err := def.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+	if as := def.Components["component/a"].Scale.Autoscale; as == nil || as.TargetCPU != 80 {
+		t.Fatalf("expected default autoscale policy, got: %s", as.String())
+	}
+
+	hidden, err := def.HideDefaults(valCtx)
+	if err != nil {
+		t.Fatalf("hiding defaults failed: %#v", err)
+	}
+	if hidden.Components["component/a"].Scale != nil {
+		t.Fatalf("expected scale defaults to be hidden, got: %s", hidden.Components["component/a"].Scale.String())
+	}
+}
+
+func TestAutoscaleDefaultsFixedScale(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/a"].Scale = &userconfig.ScaleDefinition{Min: 2, Max: 2, Placement: userconfig.DefaultPlacement}
+
+	valCtx := NewValidationContext()
+	valCtx.Autoscale = &userconfig.AutoscaleDefinition{TargetCPU: 80}
+	if err := def.Validate(valCtx); err != nil {
+		t.Fatalf("validating service failed: %#v", err)
+	}
+	if err := def.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+	if as := def.Components["component/a"].Scale.Autoscale; as != nil {
+		t.Fatalf("expected no autoscale policy for a fixed scale, got: %s", as.String())
+	}
+	if err := def.Validate(valCtx); err != nil {
+		t.Fatalf("validating service with defaults failed: %#v", err)
+	}
+
+	hidden, err := def.HideDefaults(valCtx)
+	if err != nil {
+		t.Fatalf("hiding defaults failed: %#v", err)
+	}
+	if scale := hidden.Components["component/a"].Scale; scale == nil || scale.Min != 2 || scale.Max != 2 || scale.Autoscale != nil {
+		t.Fatalf("expected fixed scale to be kept, got: %s", scale.String())
+	}
+}
+
+func TestAutoscaleDiff(t *testing.T) {
+	oldDef := ExampleDefinition()
+	newDef := ExampleDefinition()
+	newDef.Components["component/a"].Scale = &userconfig.ScaleDefinition{Autoscale: &userconfig.AutoscaleDefinition{TargetCPU: 70}}
+
+	diffInfos := userconfig.DiffInfosByType(userconfig.ServiceDiff(oldDef, newDef), userconfig.DiffTypeComponentScaleAutoscaleUpdated)
+	if len(diffInfos) != 1 {
+		t.Fatalf("expected one diff: %#v", diffInfos)
+	}
+}
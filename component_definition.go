@@ -1,5 +1,9 @@
 package userconfig
 
+import (
+	"fmt"
+)
+
 // ComponentDefinition represents either a runnable service inside a container or a
 // component configuration
 type ComponentDefinition struct {
@@ -13,18 +17,37 @@ type ComponentDefinition struct {
 	// List of ports a service exposes. E.g. 6379/tcp
 	Ports PortDefinitions `json:"ports,omitempty" description:"List of ports this service exposes."`
 
+	// Names of ports, e.g. "http" => 8080/tcp. Names are given in the ports
+	// list, e.g. { "http": "8080/tcp" }, and can be used to refer to a port.
+	PortNames PortNames `json:"-"`
+
+	// Port ranges as given in the ports list, e.g. "10000-10100/udp". They are
+	// kept to marshal the ports the way they were given.
+	PortRanges []string `json:"-"`
+
 	// Docker env to inject into docker containers.
 	Env EnvList `json:"env,omitempty" description:"List of environment variables used by this service."`
 
+	// Named secrets to inject into docker containers. Secrets are referenced by
+	// name only, so their values never show up in the definition.
+	Secrets SecretDefinitions `json:"secrets,omitempty" description:"List of named secrets to expose as environment variables or files."`
+
 	// Docker volumes to inject into docker containers.
 	Volumes VolumeDefinitions `json:"volumes,omitempty" description:"List of volumes to attach to this service."`
 
+	// Small files like configuration files to place into docker containers.
+	Files FileDefinitions `json:"files,omitempty" description:"List of files to place into the container, e.g. configuration files."`
+
 	// Arguments for processes inside docker containers.
 	Args []string `json:"args,omitempty" description:"List of arguments passed to the entry point of this service."`
 
 	// Domains to bind the port to:  domainName => port, e.g. "www.heise.de" => "80"
 	Domains V2DomainDefinitions `json:"domains,omitempty" description:"List of domains to bind exposed ports to."`
 
+	// Settings of the domains, e.g. TLS. Given in the domains, in the format
+	// domain: definition.
+	DomainSettings V2DomainSettings `json:"-"`
+
 	// Service names required by a service.
 	Links LinkDefinitions `json:"links,omitempty" description:"List of dependencies of this service."`
 
@@ -40,6 +63,53 @@ type ComponentDefinition struct {
 	// How much memory to give the container. If empty, the server may decide on a default limit.
 	MemoryLimit ByteSize `json:"memory-limit,omitempty" description:"How much memory to give the container. If empty, the server may decide on a default limit."`
 
+	// How much memory to reserve for the container. Must not be above memory-limit.
+	MemoryRequest ByteSize `json:"memory-request,omitempty" description:"How much memory to reserve for the container. Must not be above memory-limit."`
+
+	// How much swap the container may use in addition to its memory.
+	MemorySwap ByteSize `json:"memory-swap,omitempty" description:"How much swap the container may use in addition to its memory."`
+
+	// How much CPU the container may use at most, e.g. "2" or "500m". If empty, the server may decide on a default limit.
+	CPULimit CPUSize `json:"cpu-limit,omitempty" description:"How much CPU the container may use at most, e.g. '2' or '500m'. If empty, the server may decide on a default limit."`
+
+	// How much CPU to reserve for the container, e.g. "0.5" or "500m". If empty, the server may decide on a default reservation.
+	CPURequest CPUSize `json:"cpu-request,omitempty" description:"How much CPU to reserve for the container, e.g. '0.5' or '500m'. If empty, the server may decide on a default reservation."`
+
+	// How to check whether the component is alive and ready to receive traffic.
+	HealthCheck *HealthCheckDefinition `json:"healthcheck,omitempty" description:"How to check whether the component is healthy."`
+
+	// What to do when the container exits. If empty, the server may decide on a default policy.
+	Restart *RestartDefinition `json:"restart,omitempty" description:"What to do when the container exits."`
+
+	// Signal sent to the container to stop it, e.g. "SIGTERM".
+	StopSignal string `json:"stop-signal,omitempty" description:"Signal sent to the container to stop it, e.g. 'SIGTERM'."`
+
+	// Time to wait after sending the stop-signal before the container is killed, e.g. "30s".
+	StopTimeout Duration `json:"stop-timeout,omitempty" description:"Time to wait after sending the stop-signal before the container is killed, e.g. '30s'."`
+
+	// If set to "once", the component is a job that runs to completion once, e.g. a migration.
+	Run RunEnum `json:"run,omitempty" description:"If set to 'once', the component is a job that runs to completion once, e.g. a migration."`
+
+	// Cron expression of when to run the component as job, e.g. "0 3 * * *".
+	Schedule string `json:"schedule,omitempty" description:"Cron expression of when to run the component as a job, e.g. '0 3 * * *'."`
+
+	// Components that must be started, or in case of jobs be completed, before this component is started.
+	After ComponentNames `json:"after,omitempty" description:"List of components that must be started (or completed, in case of jobs) before this component."`
+
+	// Position of this component in the init sequence of its pod. Init components run to completion
+	// in ascending order, before the other components of the pod are started.
+	Init int `json:"init,omitempty" description:"Position in the init sequence of the pod. Init components run to completion in ascending order, before the other components of the pod are started."`
+
+	// How to roll out a new version of the component.
+	Update *UpdateDefinition `json:"update,omitempty" description:"How to roll out a new version of the component."`
+
+	// IDs of the lint rules to switch off for this component, e.g. "missing-memory-limit".
+	LintIgnore []string `json:"x-lint-ignore,omitempty" description:"List of lint rules to switch off for this component, e.g. 'missing-memory-limit'. Use 'all' to switch off all rules."`
+
+	// Free form comment. A line "lint-ignore: <rule>, <rule>" switches off the given lint rules,
+	// like x-lint-ignore does.
+	Comment string `json:"//,omitempty" description:"Free form comment. A line 'lint-ignore: <rule>, ...' switches off the given lint rules."`
+
 	// NOTE: In case we add new fields to the component definition, we need to
 	// implement proper diff functionality for those new fields as well.
 }
@@ -49,38 +119,96 @@ type ComponentDefinition struct {
 func (nd *ComponentDefinition) validate(valCtx *ValidationContext) error {
 	if nd.Image != nil {
 		if err := nd.Image.Validate(valCtx); err != nil {
-			return mask(err)
+			return mask(diagnosticInField(err, "image"))
 		}
 	}
 
 	if err := nd.validateMemoryLimit(valCtx); err != nil {
+		return mask(diagnosticInField(err, "memory-limit"))
+	}
+
+	if err := nd.validateMemoryRequest(valCtx); err != nil {
+		return mask(diagnosticInField(err, "memory-request"))
+	}
+
+	if err := nd.validateMemorySwap(valCtx); err != nil {
+		return mask(diagnosticInField(err, "memory-swap"))
+	}
+
+	if err := nd.validateCPU(valCtx); err != nil {
 		return mask(err)
 	}
 
 	if err := nd.Ports.Validate(valCtx); err != nil {
-		return mask(err)
+		return mask(diagnosticInField(err, "ports"))
+	}
+
+	if err := nd.PortNames.validate(nd.Ports); err != nil {
+		return mask(diagnosticInField(err, "ports"))
 	}
 
 	if err := nd.Domains.validate(nd.Ports); err != nil {
+		return mask(diagnosticInField(err, "domains"))
+	}
+
+	if err := nd.DomainSettings.validate(nd.Domains); err != nil {
+		return mask(diagnosticInField(err, "domains"))
+	}
+
+	if nd.HealthCheck != nil {
+		if err := nd.HealthCheck.validate(nd.Ports); err != nil {
+			return mask(diagnosticInField(err, "healthcheck"))
+		}
+	}
+
+	if err := nd.Secrets.validate(nd.Env); err != nil {
+		return mask(diagnosticInField(err, "secrets"))
+	}
+
+	if err := nd.Files.validate(nd.Secrets); err != nil {
+		return mask(diagnosticInField(err, "files"))
+	}
+
+	if nd.Restart != nil {
+		if err := nd.Restart.validate(); err != nil {
+			return mask(diagnosticInField(err, "restart"))
+		}
+	}
+
+	if err := nd.validateStop(valCtx); err != nil {
 		return mask(err)
 	}
 
-	if err := nd.Links.Validate(valCtx); err != nil {
+	if err := nd.validateJob(); err != nil {
 		return mask(err)
 	}
 
+	if err := nd.validateUpdate(valCtx); err != nil {
+		return mask(diagnosticInField(err, "update"))
+	}
+
+	for i, name := range nd.After {
+		if err := name.Validate(); err != nil {
+			return newDiagnostic(InvalidComponentDefinitionError, "invalid after: %s", err.Error()).withField(fmt.Sprintf("after[%d]", i)).withValue(name)
+		}
+	}
+
+	if err := nd.Links.Validate(valCtx); err != nil {
+		return mask(diagnosticInField(err, "links"))
+	}
+
 	if err := nd.Volumes.validate(valCtx); err != nil {
-		return mask(err)
+		return mask(diagnosticInField(err, "volumes"))
 	}
 
 	if nd.Scale != nil {
 		if err := nd.Scale.validate(valCtx); err != nil {
-			return mask(err)
+			return mask(diagnosticInField(err, "scale"))
 		}
 	}
 
 	if err := nd.Expose.validate(); err != nil {
-		return mask(err)
+		return mask(diagnosticInField(err, "expose"))
 	}
 
 	return nil
@@ -95,7 +223,7 @@ func (nd *ComponentDefinition) validateMemoryLimit(valCtx *ValidationContext) er
 	// Is the value itself valid?
 	value, err := nd.MemoryLimit.Bytes()
 	if err != nil {
-		return mask(InvalidMemoryLimitError)
+		return newDiagnostic(InvalidMemoryLimitError, "").withValue(nd.MemoryLimit)
 	}
 
 	// If we have a validationContext, compare against boundaries
@@ -105,7 +233,7 @@ func (nd *ComponentDefinition) validateMemoryLimit(valCtx *ValidationContext) er
 
 	if !valCtx.EnableUserMemoryLimit {
 		if !nd.MemoryLimit.IsEmpty() {
-			return maskf(InvalidMemoryLimitError, "Providing a 'memory-limit' is not enabled.")
+			return newDiagnostic(InvalidMemoryLimitError, "Providing a 'memory-limit' is not enabled.").withValue(nd.MemoryLimit).withFix("remove the memory-limit")
 		}
 		return nil
 	}
@@ -120,19 +248,238 @@ func (nd *ComponentDefinition) validateMemoryLimit(valCtx *ValidationContext) er
 	}
 
 	if value < min {
-		return maskf(InvalidMemoryLimitError, "memory-limit must be above %s", valCtx.MinMemoryLimit.String())
+		return newDiagnostic(InvalidMemoryLimitError, "memory-limit must be above %s", valCtx.MinMemoryLimit.String()).withValue(nd.MemoryLimit)
+	}
+	if value > max {
+		return newDiagnostic(InvalidMemoryLimitError, "memory-limit must be below %s", valCtx.MaxMemoryLimit.String()).withValue(nd.MemoryLimit)
+	}
+	return nil
+}
+
+func (nd *ComponentDefinition) validateMemoryRequest(valCtx *ValidationContext) error {
+	// An empty memory-request is okay
+	if nd.MemoryRequest.IsEmpty() {
+		return nil
+	}
+
+	// Is the value itself valid?
+	value, err := nd.MemoryRequest.Bytes()
+	if err != nil {
+		return newDiagnostic(InvalidMemoryRequestError, "").withValue(nd.MemoryRequest)
+	}
+
+	// A reservation above the limit can never be used
+	if !nd.MemoryLimit.IsEmpty() {
+		limit, err := nd.MemoryLimit.Bytes()
+		if err == nil && value > limit {
+			return newDiagnostic(InvalidMemoryRequestError, "memory-request '%s' must not be above memory-limit '%s'", nd.MemoryRequest, nd.MemoryLimit).withValue(nd.MemoryRequest).withFix("lower the memory-request to at most '%s'", nd.MemoryLimit)
+		}
+	}
+
+	// If we have a validationContext, compare against boundaries
+	if valCtx == nil {
+		return nil
+	}
+
+	if !valCtx.EnableUserMemoryLimit {
+		return newDiagnostic(InvalidMemoryRequestError, "Providing a 'memory-request' is not enabled.").withValue(nd.MemoryRequest).withFix("remove the memory-request")
+	}
+
+	min, err := valCtx.MinMemoryLimit.Bytes()
+	if err != nil {
+		panic("Provided minimum memory-limit is invalid: " + err.Error())
+	}
+	max, err := valCtx.MaxMemoryLimit.Bytes()
+	if err != nil {
+		panic("Provided maximum memory-limit is invalid: " + err.Error())
+	}
+
+	if value < min {
+		return newDiagnostic(InvalidMemoryRequestError, "memory-request must be above %s", valCtx.MinMemoryLimit.String()).withValue(nd.MemoryRequest)
+	}
+	if value > max {
+		return newDiagnostic(InvalidMemoryRequestError, "memory-request must be below %s", valCtx.MaxMemoryLimit.String()).withValue(nd.MemoryRequest)
+	}
+	return nil
+}
+
+func (nd *ComponentDefinition) validateMemorySwap(valCtx *ValidationContext) error {
+	// An empty memory-swap is okay
+	if nd.MemorySwap.IsEmpty() {
+		return nil
+	}
+
+	// Is the value itself valid?
+	value, err := nd.MemorySwap.Bytes()
+	if err != nil {
+		return newDiagnostic(InvalidMemorySwapError, "").withValue(nd.MemorySwap)
+	}
+
+	// If we have a validationContext, compare against boundaries
+	if valCtx == nil {
+		return nil
+	}
+
+	if !valCtx.EnableUserMemorySwap {
+		return newDiagnostic(InvalidMemorySwapError, "Providing a 'memory-swap' is not enabled.").withValue(nd.MemorySwap).withFix("remove the memory-swap")
+	}
+
+	min, err := valCtx.MinMemorySwap.Bytes()
+	if err != nil {
+		panic("Provided minimum memory-swap is invalid: " + err.Error())
+	}
+	max, err := valCtx.MaxMemorySwap.Bytes()
+	if err != nil {
+		panic("Provided maximum memory-swap is invalid: " + err.Error())
+	}
+
+	if value < min {
+		return newDiagnostic(InvalidMemorySwapError, "memory-swap must be above %s", valCtx.MinMemorySwap.String()).withValue(nd.MemorySwap)
 	}
 	if value > max {
-		return maskf(InvalidMemoryLimitError, "memory-limit must be below %s", valCtx.MaxMemoryLimit.String())
+		return newDiagnostic(InvalidMemorySwapError, "memory-swap must be below %s", valCtx.MaxMemorySwap.String()).withValue(nd.MemorySwap)
+	}
+	return nil
+}
+
+// validateMemoryRequestsInPods checks that the sum of all memory requests
+// within a pod does not exceed the maximum allowed per pod.
+func (nds *ComponentDefinitions) validateMemoryRequestsInPods(valCtx *ValidationContext) error {
+	if valCtx == nil || valCtx.MaxPodMemoryRequest.IsEmpty() {
+		return nil
+	}
+
+	max, err := valCtx.MaxPodMemoryRequest.Bytes()
+	if err != nil {
+		panic("Provided maximum pod memory-request is invalid: " + err.Error())
+	}
+
+	for componentName, componentDef := range *nds {
+		if !componentDef.IsPodRoot() {
+			continue
+		}
+
+		// Sum up all memory requests in this pod
+		podComponents, err := nds.PodComponents(componentName)
+		if err != nil {
+			return mask(err)
+		}
+		var sum uint64
+		for name, c := range podComponents {
+			if c.MemoryRequest.IsEmpty() {
+				// No memory request set
+				continue
+			}
+			value, err := c.MemoryRequest.Bytes()
+			if err != nil {
+				return newDiagnostic(InvalidMemoryRequestError, "").withComponent(name).withValue(c.MemoryRequest).withField("memory-request")
+			}
+			sum += value
+		}
+
+		if sum > max {
+			return newDiagnostic(InvalidMemoryRequestError, "sum of memory-requests in pod under '%s' must be below %s", componentName.String(), valCtx.MaxPodMemoryRequest.String()).withComponent(componentName).withField("memory-request").withValue(sum)
+		}
+	}
+
+	// No errors detected
+	return nil
+}
+
+func (nd *ComponentDefinition) validateCPU(valCtx *ValidationContext) error {
+	// An empty cpu-limit and cpu-request is okay
+	if nd.CPULimit.IsEmpty() && nd.CPURequest.IsEmpty() {
+		return nil
+	}
+
+	// Are the values themselves valid?
+	var limit, request uint64
+	var err error
+	if !nd.CPULimit.IsEmpty() {
+		limit, err = nd.CPULimit.Millis()
+		if err != nil {
+			return newDiagnostic(InvalidCPULimitError, "invalid cpu-limit '%s'", nd.CPULimit).withField("cpu-limit").withValue(nd.CPULimit)
+		}
+	}
+	if !nd.CPURequest.IsEmpty() {
+		request, err = nd.CPURequest.Millis()
+		if err != nil {
+			return newDiagnostic(InvalidCPURequestError, "invalid cpu-request '%s'", nd.CPURequest).withField("cpu-request").withValue(nd.CPURequest)
+		}
+	}
+
+	if !nd.CPULimit.IsEmpty() && !nd.CPURequest.IsEmpty() && request > limit {
+		return newDiagnostic(InvalidCPURequestError, "cpu-request '%s' must not be above cpu-limit '%s'", nd.CPURequest, nd.CPULimit).withField("cpu-request").withValue(nd.CPURequest).withFix("lower the cpu-request to at most '%s'", nd.CPULimit)
+	}
+
+	// If we have a validationContext, compare against boundaries
+	if valCtx == nil {
+		return nil
+	}
+
+	if !valCtx.EnableUserCPULimit {
+		if !nd.CPULimit.IsEmpty() {
+			return newDiagnostic(InvalidCPULimitError, "Providing a 'cpu-limit' is not enabled.").withField("cpu-limit").withValue(nd.CPULimit).withFix("remove the cpu-limit")
+		}
+		return newDiagnostic(InvalidCPURequestError, "Providing a 'cpu-request' is not enabled.").withField("cpu-request").withValue(nd.CPURequest).withFix("remove the cpu-request")
+	}
+
+	min, err := valCtx.MinCPULimit.Millis()
+	if err != nil {
+		panic("Provided minimum cpu-limit is invalid: " + err.Error())
+	}
+	max, err := valCtx.MaxCPULimit.Millis()
+	if err != nil {
+		panic("Provided maximum cpu-limit is invalid: " + err.Error())
+	}
+
+	if !nd.CPULimit.IsEmpty() {
+		if limit < min {
+			return newDiagnostic(InvalidCPULimitError, "cpu-limit must be above %s", valCtx.MinCPULimit.String()).withField("cpu-limit").withValue(nd.CPULimit)
+		}
+		if limit > max {
+			return newDiagnostic(InvalidCPULimitError, "cpu-limit must be below %s", valCtx.MaxCPULimit.String()).withField("cpu-limit").withValue(nd.CPULimit)
+		}
+	}
+	if !nd.CPURequest.IsEmpty() {
+		if request < min {
+			return newDiagnostic(InvalidCPURequestError, "cpu-request must be above %s", valCtx.MinCPULimit.String()).withField("cpu-request").withValue(nd.CPURequest)
+		}
+		if request > max {
+			return newDiagnostic(InvalidCPURequestError, "cpu-request must be below %s", valCtx.MaxCPULimit.String()).withField("cpu-request").withValue(nd.CPURequest)
+		}
 	}
 	return nil
 }
 
+// defaultsValidationContext returns the validation context to take the scale
+// and restart defaults of this component from. Jobs never run more than one
+// instance and, like init components, are not restarted always.
+func (nd *ComponentDefinition) defaultsValidationContext(valCtx *ValidationContext) *ValidationContext {
+	if nd.IsJob() {
+		return jobValidationContext(valCtx)
+	}
+	if nd.IsInit() {
+		return initValidationContext(valCtx)
+	}
+	return valCtx
+}
+
 func (nd *ComponentDefinition) hideDefaults(valCtx *ValidationContext) *ComponentDefinition {
+	defaultsValCtx := nd.defaultsValidationContext(valCtx)
+
 	if nd.Scale != nil {
-		nd.Scale = nd.Scale.hideDefaults(valCtx)
+		nd.Scale = nd.Scale.hideDefaults(defaultsValCtx)
 	}
 
+	if nd.HealthCheck != nil {
+		nd.HealthCheck = nd.HealthCheck.hideDefaults(valCtx)
+	}
+
+	nd.hideRestartDefaults(defaultsValCtx)
+
+	nd.hideCPUDefaults(valCtx)
+
 	return nd
 }
 
@@ -142,7 +489,85 @@ func (nd *ComponentDefinition) setDefaults(valCtx *ValidationContext) {
 		nd.Scale = &ScaleDefinition{}
 	}
 
-	nd.Scale.setDefaults(valCtx)
+	defaultsValCtx := nd.defaultsValidationContext(valCtx)
+
+	nd.Scale.setDefaults(defaultsValCtx)
+
+	nd.setCPUDefaults(valCtx)
+
+	if nd.HealthCheck != nil {
+		nd.HealthCheck.setDefaults(valCtx)
+	}
+
+	nd.setRestartDefaults(defaultsValCtx)
+}
+
+// setCPUDefaults applies the default cpu-limit and cpu-request. Defaults are
+// only applied if users are allowed to configure CPU, since the validation
+// would reject them otherwise.
+func (nd *ComponentDefinition) setCPUDefaults(valCtx *ValidationContext) {
+	if !valCtx.EnableUserCPULimit {
+		return
+	}
+
+	explicitLimit, explicitRequest := !nd.CPULimit.IsEmpty(), !nd.CPURequest.IsEmpty()
+	if !explicitLimit {
+		nd.CPULimit = valCtx.DefaultCPULimit
+	}
+	if !explicitRequest {
+		nd.CPURequest = valCtx.DefaultCPURequest
+	}
+
+	limit, err := nd.CPULimit.Millis()
+	if err != nil {
+		return
+	}
+	request, err := nd.CPURequest.Millis()
+	if err != nil {
+		return
+	}
+	if request <= limit {
+		return
+	}
+
+	// A default reservation must not exceed an explicitly set limit, and a
+	// default limit must not be below an explicitly set reservation.
+	if !explicitRequest {
+		nd.CPURequest = nd.CPULimit
+	} else if !explicitLimit {
+		nd.CPULimit = nd.CPURequest
+	}
+}
+
+// hideCPUDefaults removes the cpu-limit and cpu-request applied by
+// setCPUDefaults, including a default limit raised to the request and a
+// default request capped at the limit.
+func (nd *ComponentDefinition) hideCPUDefaults(valCtx *ValidationContext) {
+	if !valCtx.EnableUserCPULimit {
+		return
+	}
+
+	hideLimit := nd.CPULimit.Equals(valCtx.DefaultCPULimit)
+	hideRequest := nd.CPURequest.Equals(valCtx.DefaultCPURequest)
+	if nd.CPULimit.Equals(nd.CPURequest) {
+		limit, limitErr := nd.CPULimit.Millis()
+		defaultLimit, defaultLimitErr := valCtx.DefaultCPULimit.Millis()
+		defaultRequest, defaultRequestErr := valCtx.DefaultCPURequest.Millis()
+		if limitErr == nil && defaultLimitErr == nil && defaultRequestErr == nil {
+			if limit > defaultLimit {
+				hideLimit = true
+			} else if limit < defaultRequest {
+				hideRequest = true
+			}
+		}
+	}
+
+	if hideLimit {
+		nd.CPULimit = ""
+	}
+	if hideRequest {
+		nd.CPURequest = ""
+	}
 }
 
 // IsComponent returns true if the component has a defined container image, false otherwise.
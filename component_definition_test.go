@@ -269,3 +269,178 @@ func Test_AllDefsPerPod(t *testing.T) {
 		t.Fatalf("AllDefsPerPod failed: unexpected result")
 	}
 }
+
+func Test_CPU_Validation(t *testing.T) {
+	valCtx := NewValidationContext()
+	valCtx.EnableUserCPULimit = true
+	valCtx.MinCPULimit = userconfig.CPUSize("100m")
+	valCtx.MaxCPULimit = userconfig.CPUSize("4")
+
+	tests := []struct {
+		Limit   userconfig.CPUSize
+		Request userconfig.CPUSize
+		Check   func(error) bool
+	}{
+		{"", "", nil},
+		{"2", "", nil},
+		{"", "0.5", nil},
+		{"1", "500m", nil},
+		{"1", "1000m", nil},
+		{"two", "", userconfig.IsInvalidCPULimit},
+		{"", "half", userconfig.IsInvalidCPURequest},
+		{"500m", "1", userconfig.IsInvalidCPURequest},
+		{"50m", "", userconfig.IsInvalidCPULimit},
+		{"8", "", userconfig.IsInvalidCPULimit},
+		{"", "5", userconfig.IsInvalidCPURequest},
+	}
+
+	for i, test := range tests {
+		def := ExampleDefinition()
+		def.Components["component/a"].CPULimit = test.Limit
+		def.Components["component/a"].CPURequest = test.Request
+
+		err := def.Validate(valCtx)
+		if test.Check == nil && err != nil {
+			t.Fatalf("test %d: expected definition to be valid, got error: %#v", i, err)
+		}
+		if test.Check != nil && !test.Check(err) {
+			t.Fatalf("test %d: expected different error, got: %#v", i, err)
+		}
+	}
+}
+
+func Test_CPU_NotEnabled(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/a"].CPULimit = userconfig.CPUSize("1")
+
+	if err := def.Validate(NewValidationContext()); !userconfig.IsInvalidCPULimit(err) {
+		t.Fatalf("expected error to be InvalidCPULimitError, got: %#v", err)
+	}
+}
+
+func Test_CPU_Defaults(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/a"].CPULimit = userconfig.CPUSize("250m")
+	def.Components["component/c"] = &userconfig.ComponentDefinition{
+		Image:      userconfig.MustParseImageDefinition("registry.giantswarm.io/giantswarm/c:0.10.0"),
+		CPURequest: userconfig.CPUSize("2"),
+	}
+
+	valCtx := NewValidationContext()
+	valCtx.EnableUserCPULimit = true
+	valCtx.MinCPULimit = userconfig.CPUSize("100m")
+	valCtx.MaxCPULimit = userconfig.CPUSize("4")
+	valCtx.DefaultCPULimit = userconfig.CPUSize("1")
+	valCtx.DefaultCPURequest = userconfig.CPUSize("500m")
+
+	if err := def.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+
+	componentA := def.Components["component/a"]
+	if componentA.CPULimit != "250m" || componentA.CPURequest != "250m" {
+		t.Fatalf("expected default request to be capped at explicit limit, got '%s' and '%s'", componentA.CPULimit, componentA.CPURequest)
+	}
+	componentB := def.Components["component/b"]
+	if componentB.CPULimit != valCtx.DefaultCPULimit || componentB.CPURequest != valCtx.DefaultCPURequest {
+		t.Fatalf("expected defaults to be set, got '%s' and '%s'", componentB.CPULimit, componentB.CPURequest)
+	}
+	componentC := def.Components["component/c"]
+	if componentC.CPULimit != "2" || componentC.CPURequest != "2" {
+		t.Fatalf("expected default limit to be raised to explicit request, got '%s' and '%s'", componentC.CPULimit, componentC.CPURequest)
+	}
+
+	if err := def.Validate(valCtx); err != nil {
+		t.Fatalf("validating service failed: %#v", err)
+	}
+
+	hidden, err := def.HideDefaults(valCtx)
+	if err != nil {
+		t.Fatalf("hiding defaults failed: %#v", err)
+	}
+	if hidden.Components["component/b"].CPULimit != "" || hidden.Components["component/b"].CPURequest != "" {
+		t.Fatalf("cpu defaults not hidden")
+	}
+	if hidden.Components["component/a"].CPULimit != "250m" || hidden.Components["component/a"].CPURequest != "" {
+		t.Fatalf("expected explicit cpu-limit only, got '%s' and '%s'", hidden.Components["component/a"].CPULimit, hidden.Components["component/a"].CPURequest)
+	}
+	if hidden.Components["component/c"].CPULimit != "" || hidden.Components["component/c"].CPURequest != "2" {
+		t.Fatalf("expected explicit cpu-request only, got '%s' and '%s'", hidden.Components["component/c"].CPULimit, hidden.Components["component/c"].CPURequest)
+	}
+}
+
+func Test_MemoryRequestAndSwap_Validation(t *testing.T) {
+	valCtx := NewValidationContext()
+	valCtx.EnableUserMemoryLimit = true
+	valCtx.MinMemoryLimit = userconfig.ByteSize("64 mb")
+	valCtx.MaxMemoryLimit = userconfig.ByteSize("4 gb")
+	valCtx.EnableUserMemorySwap = true
+	valCtx.MinMemorySwap = userconfig.ByteSize("0")
+	valCtx.MaxMemorySwap = userconfig.ByteSize("1 gb")
+
+	tests := []struct {
+		Limit   userconfig.ByteSize
+		Request userconfig.ByteSize
+		Swap    userconfig.ByteSize
+		Check   func(error) bool
+	}{
+		{"", "", "", nil},
+		{"1 gb", "512 mb", "", nil},
+		{"", "512 mb", "256 mb", nil},
+		{"1 gb", "1 gb", "1 gb", nil},
+		{"512 mb", "1 gb", "", userconfig.IsInvalidMemoryRequest},
+		{"", "1 egg", "", userconfig.IsInvalidMemoryRequest},
+		{"", "1 mb", "", userconfig.IsInvalidMemoryRequest},
+		{"", "8 gb", "", userconfig.IsInvalidMemoryRequest},
+		{"", "", "2 gb", userconfig.IsInvalidMemorySwap},
+		{"", "", "lots", userconfig.IsInvalidMemorySwap},
+	}
+
+	for i, test := range tests {
+		def := ExampleDefinition()
+		def.Components["component/a"].MemoryLimit = test.Limit
+		def.Components["component/a"].MemoryRequest = test.Request
+		def.Components["component/a"].MemorySwap = test.Swap
+
+		err := def.Validate(valCtx)
+		if test.Check == nil && err != nil {
+			t.Fatalf("test %d: expected definition to be valid, got error: %#v", i, err)
+		}
+		if test.Check != nil && !test.Check(err) {
+			t.Fatalf("test %d: expected different error, got: %#v", i, err)
+		}
+	}
+}
+
+func Test_MemorySwap_NotEnabled(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/a"].MemorySwap = userconfig.ByteSize("1 gb")
+
+	if err := def.Validate(NewValidationContext()); !userconfig.IsInvalidMemorySwap(err) {
+		t.Fatalf("expected error to be InvalidMemorySwapError, got: %#v", err)
+	}
+}
+
+func Test_MemoryRequest_SumInPod(t *testing.T) {
+	valCtx := NewValidationContext()
+	valCtx.EnableUserMemoryLimit = true
+	valCtx.MinMemoryLimit = userconfig.ByteSize("64 mb")
+	valCtx.MaxMemoryLimit = userconfig.ByteSize("4 gb")
+	valCtx.MaxPodMemoryRequest = userconfig.ByteSize("2 gb")
+
+	service := testService()
+	service.Components["root"] = setPod(&userconfig.ComponentDefinition{}, userconfig.PodChildren)
+	service.Components["root/a"] = testComponent()
+	service.Components["root/a"].MemoryRequest = userconfig.ByteSize("1 gb")
+	service.Components["root/b"] = testComponent()
+	service.Components["root/b"].MemoryRequest = userconfig.ByteSize("1 gb")
+
+	if err := service.Validate(valCtx); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+
+	service.Components["root/b"].MemoryRequest = userconfig.ByteSize("1500 mb")
+	if err := service.Validate(valCtx); !userconfig.IsInvalidMemoryRequest(err) {
+		t.Fatalf("expected error to be InvalidMemoryRequestError, got: %#v", err)
+	}
+}
@@ -3,16 +3,20 @@ package userconfig
 type ComponentDefinitions map[ComponentName]*ComponentDefinition
 
 func (nds ComponentDefinitions) validate(valCtx *ValidationContext) error {
+	if err := nds.resolvePortNames(); err != nil {
+		return mask(err)
+	}
+
 	for componentName, _ := range nds {
 		if err := componentName.Validate(); err != nil {
-			return mask(err)
+			return mask(diagnosticInComponent(err, componentName))
 		}
 
 		// because of defaulting when validating we need to reference the to the
 		// address of the component. so its changes effect the app definition after
 		// parsing.
 		if err := nds[componentName].validate(valCtx); err != nil {
-			return mask(err)
+			return mask(diagnosticInComponent(err, componentName))
 		}
 	}
 
@@ -24,6 +28,10 @@ func (nds ComponentDefinitions) validate(valCtx *ValidationContext) error {
 		return mask(err)
 	}
 
+	if err := nds.validateAfter(); err != nil {
+		return mask(err)
+	}
+
 	if err := nds.validateVolumesRefs(); err != nil {
 		return mask(err)
 	}
@@ -32,6 +40,10 @@ func (nds ComponentDefinitions) validate(valCtx *ValidationContext) error {
 		return mask(err)
 	}
 
+	if err := nds.validateMountPointConflicts(); err != nil {
+		return mask(err)
+	}
+
 	// Check for duplicate exposed ports in pods
 	if err := nds.validateUniquePortsInPods(); err != nil {
 		return mask(err)
@@ -52,6 +64,36 @@ func (nds ComponentDefinitions) validate(valCtx *ValidationContext) error {
 		return mask(err)
 	}
 
+	// Check affinities
+	if err := nds.validatePlacementConstraints(); err != nil {
+		return mask(err)
+	}
+
+	// Check restart policies in pods
+	if err := nds.validateRestartPolicyInPods(); err != nil {
+		return mask(err)
+	}
+
+	// Check update strategies in pods
+	if err := nds.validateUpdateStrategyInPods(); err != nil {
+		return mask(err)
+	}
+
+	// Check jobs in pods
+	if err := nds.validateJobsInPods(); err != nil {
+		return mask(err)
+	}
+
+	// Check memory reservations in pods
+	if err := nds.validateMemoryRequestsInPods(valCtx); err != nil {
+		return mask(err)
+	}
+
+	// Check that domains are bound once over all components
+	if err := nds.validateUniqueDomains(); err != nil {
+		return mask(err)
+	}
+
 	// Check leafs
 	if err := nds.validateLeafs(); err != nil {
 		return mask(err)
@@ -62,6 +104,14 @@ func (nds ComponentDefinitions) validate(valCtx *ValidationContext) error {
 
 // hideDefaults goes over each component and removes default values.
 func (nds ComponentDefinitions) hideDefaults(valCtx *ValidationContext) ComponentDefinitions {
+	for componentName, component := range nds {
+		if component.IsPodRoot() {
+			if podComponents, err := nds.PodComponents(componentName); err == nil {
+				nds.hideSharedSettingsInPod(podComponents)
+			}
+		}
+	}
+
 	for componentName, component := range nds {
 		nds[componentName] = component.hideDefaults(valCtx)
 	}
@@ -97,7 +147,9 @@ func (nds ComponentDefinitions) shareExplicitSettingsInPod(podComponents Compone
 	// Find explicitly set values
 	localValCtx := *valCtx
 	hasExplicitValues := false
-	for _, c := range podComponents {
+	var constraints *PlacementConstraints
+	for _, name := range orderedComponentKeys(podComponents) {
+		c := podComponents[ComponentName(name)]
 		if c.Scale != nil {
 			if c.Scale.Min != 0 {
 				localValCtx.MinScaleSize = c.Scale.Min
@@ -111,12 +163,64 @@ func (nds ComponentDefinitions) shareExplicitSettingsInPod(podComponents Compone
 				localValCtx.Placement = c.Scale.Placement
 				hasExplicitValues = true
 			}
+			if c.Scale.Autoscale != nil {
+				localValCtx.Autoscale = c.Scale.Autoscale
+				hasExplicitValues = true
+			}
+			if c.Scale.Constraints.hasMachineConstraints() && constraints == nil {
+				constraints = c.Scale.Constraints.machineConstraints()
+				hasExplicitValues = true
+			}
+		}
+		if c.Restart != nil && !c.IsInit() {
+			localValCtx.RestartPolicy = c.Restart.Policy
+			localValCtx.RestartMaxRetries = c.Restart.MaxRetries
+			hasExplicitValues = true
+		}
+		if !c.StopTimeout.IsEmpty() {
+			localValCtx.StopTimeout = c.StopTimeout
+			hasExplicitValues = true
 		}
 	}
 	// Are there are any explicit values?
 	if hasExplicitValues {
 		podComponents.doSetDefaults(&localValCtx)
 	}
+	// Constraints on the machines have no service wide default, share them
+	// directly. Affinities are not shared, a component cannot refer to itself.
+	if constraints != nil {
+		for _, c := range podComponents {
+			if c.Scale.Constraints == nil {
+				c.Scale.Constraints = &PlacementConstraints{}
+			}
+			if !c.Scale.Constraints.hasMachineConstraints() {
+				c.Scale.Constraints.setMachineConstraints(constraints)
+			}
+		}
+	}
+}
+
+// hideSharedSettingsInPod removes the constraints on the machines, that
+// shareExplicitSettingsInPod copied from the first member of the given pod
+// that sets them.
+func (nds ComponentDefinitions) hideSharedSettingsInPod(podComponents ComponentDefinitions) {
+	var constraints *PlacementConstraints
+	for _, name := range orderedComponentKeys(podComponents) {
+		c := podComponents[ComponentName(name)]
+		if c.Scale == nil || !c.Scale.Constraints.hasMachineConstraints() {
+			continue
+		}
+		if constraints == nil {
+			constraints = c.Scale.Constraints.machineConstraints()
+			continue
+		}
+		if c.Scale.Constraints.machineConstraints().Equals(constraints) {
+			c.Scale.Constraints.setMachineConstraints(&PlacementConstraints{})
+			if c.Scale.Constraints.isEmpty() {
+				c.Scale.Constraints = nil
+			}
+		}
+	}
 }
 
 // doSetDefaults goes over each component and applies default values
@@ -347,7 +451,8 @@ func (nds *ComponentDefinitions) ComponentNames() ComponentNames {
 //   - prevent duplicated lists, once a component definition is present in one
 //     list, it is not present in other lists.
 // The resulting maps are sorted such that components that link to other components are
-// found after the component that they link to.
+// found after the component that they link to. The same applies to components
+// that list other components in after.
 func (nds *ComponentDefinitions) AllDefsPerPod(names ComponentNames) ([]ComponentDefinitions, error) {
 	defsPerPod := []ComponentDefinitions{}
 
@@ -385,7 +490,7 @@ first:
 }
 
 // sortByLinks orders the given list such that components with links to other components
-// come later that the components they link to
+// come later that the components they link to, or list in after
 func (nds *ComponentDefinitions) sortByLinks(defs []ComponentDefinitions) ([]ComponentDefinitions, error) {
 	// Re-order such that components that link to other components are after before those other components
 	for i := 0; i < len(defs); {
@@ -408,7 +513,7 @@ func (nds *ComponentDefinitions) sortByLinks(defs []ComponentDefinitions) ([]Com
 }
 
 // getIndexFromLinks returns the index (in cs) where the given component should be
-// placed in order to be after all of the component it links to
+// placed in order to be after all of the component it links to, or lists in after
 func (nds *ComponentDefinitions) getIndexFromLinks(def ComponentDefinitions, defs []ComponentDefinitions) (int, error) {
 	newIndex := -1
 
@@ -444,6 +549,17 @@ func (nds *ComponentDefinitions) getIndexFromLinks(def ComponentDefinitions, def
 				newIndex = implDefIndex
 			}
 		}
+		for _, name := range c.After {
+			afterIndex, err := indexOf(name)
+			if err != nil {
+				// component is not in our lists, so we don't have to care about it
+				continue
+			}
+
+			if afterIndex > newIndex {
+				newIndex = afterIndex
+			}
+		}
 	}
 	return newIndex, nil
 }
@@ -0,0 +1,121 @@
+package userconfig
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+var (
+	InvalidCPUSizeFormatError = errgo.Newf("Invalid CPU format")
+)
+
+func IsInvalidCPUSizeFormat(err error) bool {
+	return errgo.Cause(err) == InvalidCPUSizeFormatError
+}
+
+// CPUSize describes an amount of CPU, either as a number of cores ("2",
+// "0.5") or as millicores ("500m"). One core equals 1000 millicores.
+type CPUSize string
+
+// UnmarshalJSON supports parsing a CPUSize as string and as number.
+func (c *CPUSize) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return mask(err)
+		}
+		*c = CPUSize(s)
+		return nil
+	}
+
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return errgo.WithCausef(err, InvalidCPUSizeFormatError, "Input: %s", string(data))
+	}
+	*c = CPUSize(formatCPUNumber(f))
+
+	return nil
+}
+
+// IsEmpty returns true if the underlying string is empty
+func (c CPUSize) IsEmpty() bool {
+	return c.String() == ""
+}
+
+// Valid returns a bool indicating whether this CPUSize value can successfully be parsed.
+func (c CPUSize) Valid() bool {
+	if c.IsEmpty() {
+		return false
+	}
+	_, err := c.Millis()
+	return err == nil
+}
+
+// Millis parses the number of millicores described by this CPUSize.
+// If the value of c is unparsable, an error is returned.
+// Example: if the value contains "0.5" or "500m", 500 will be returned.
+func (c CPUSize) Millis() (uint64, error) {
+	s := strings.TrimSpace(string(c))
+
+	if strings.HasSuffix(s, "m") {
+		value, err := strconv.ParseUint(strings.TrimSpace(s[:len(s)-1]), 10, 64)
+		if err != nil {
+			return 0, errgo.WithCausef(nil, InvalidCPUSizeFormatError, "Input: %s", string(c))
+		}
+		return value, nil
+	}
+
+	// Cores are given with at most 3 decimals, since we cannot express
+	// anything below one millicore.
+	parts := strings.Split(s, ".")
+	if len(parts) > 2 || parts[0] == "" {
+		return 0, errgo.WithCausef(nil, InvalidCPUSizeFormatError, "Input: %s", string(c))
+	}
+	cores, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, errgo.WithCausef(nil, InvalidCPUSizeFormatError, "Input: %s", string(c))
+	}
+	var millis uint64
+	if len(parts) == 2 {
+		fraction := parts[1]
+		if fraction == "" || len(fraction) > 3 {
+			return 0, errgo.WithCausef(nil, InvalidCPUSizeFormatError, "Input: %s", string(c))
+		}
+		fraction = fraction + strings.Repeat("0", 3-len(fraction))
+		millis, err = strconv.ParseUint(fraction, 10, 64)
+		if err != nil {
+			return 0, errgo.WithCausef(nil, InvalidCPUSizeFormatError, "Input: %s", string(c))
+		}
+	}
+
+	return cores*1000 + millis, nil
+}
+
+// Equals returns true if both values describe the same amount of CPU.
+func (c CPUSize) Equals(other CPUSize) bool {
+	myMillis, err := c.Millis()
+	if err != nil {
+		return false
+	}
+
+	otherMillis, err := other.Millis()
+	if err != nil {
+		return false
+	}
+
+	return myMillis == otherMillis
+}
+
+// String returns the string value of this CPUSize as initially provided.
+func (c CPUSize) String() string {
+	return string(c)
+}
+
+// formatCPUNumber formats a CPU value given as JSON number, e.g. 0.5 as
+// "0.5".
+func formatCPUNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
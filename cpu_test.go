@@ -0,0 +1,74 @@
+package userconfig
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCPUSizeParse(t *testing.T) {
+	tests := []struct {
+		input string
+		valid bool
+		value uint64
+	}{
+		{"2", true, 2000},
+		{" 2 ", true, 2000},
+		{"0.5", true, 500},
+		{"1.25", true, 1250},
+		{"0.001", true, 1},
+		{"500m", true, 500},
+		{"2000m", true, 2000},
+		{"0", true, 0},
+		{"", false, 0},
+		{"m", false, 0},
+		{".5", false, 0},
+		{"1.", false, 0},
+		{"0.0005", false, 0},
+		{"-1", false, 0},
+		{"1.5m", false, 0},
+		{"2 cores", false, 0},
+	}
+
+	for idx, test := range tests {
+		c := CPUSize(test.input)
+
+		v, err := c.Millis()
+		if test.valid {
+			if err != nil {
+				t.Errorf("Test %d, Millis(%s) returned unexpected error: %v", idx, test.input, err)
+			}
+		} else {
+			if err == nil {
+				t.Errorf("Test %d, Millis(%s) expected error, but received nil.", idx, test.input)
+			} else if !IsInvalidCPUSizeFormat(err) {
+				t.Errorf("Test %d, Millis(%s) expected InvalidCPUSizeFormatError, got %v", idx, test.input, err)
+			}
+		}
+		if v != test.value {
+			t.Errorf("Test %d, Millis(%s): Expected %v, got %v\n", idx, test.input, test.value, v)
+		}
+	}
+}
+
+func TestCPUSizeUnmarshal(t *testing.T) {
+	tests := []struct {
+		input  string
+		result CPUSize
+	}{
+		{`"500m"`, "500m"},
+		{`"0.5"`, "0.5"},
+		{`0.5`, "0.5"},
+		{`2`, "2"},
+		{`2.0`, "2"},
+	}
+
+	for idx, test := range tests {
+		var c CPUSize
+		if err := json.Unmarshal([]byte(test.input), &c); err != nil {
+			t.Errorf("Test %d, Unmarshal(%s) returned unexpected error: %v", idx, test.input, err)
+		}
+		if c != test.result {
+			t.Errorf("Test %d, Unmarshal(%s): Expected %s, got %s", idx, test.input, test.result, c)
+		}
+	}
+}
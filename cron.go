@@ -0,0 +1,95 @@
+package userconfig
+
+import (
+	"strconv"
+	"strings"
+)
+
+// cronField describes the allowed range of a single field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+var cronMacros = []string{
+	"@yearly",
+	"@annually",
+	"@monthly",
+	"@weekly",
+	"@daily",
+	"@midnight",
+	"@hourly",
+}
+
+// validateCronSchedule checks that the given schedule is a valid cron
+// expression with 5 fields, e.g. "0 3 * * *", or one of the macros like
+// "@daily".
+func validateCronSchedule(schedule string) error {
+	if strings.HasPrefix(schedule, "@") {
+		for _, m := range cronMacros {
+			if schedule == m {
+				return nil
+			}
+		}
+		return maskf(InvalidJobDefinitionError, "unknown schedule '%s'", schedule)
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return maskf(InvalidJobDefinitionError, "schedule '%s' must have %d fields", schedule, len(cronFields))
+	}
+
+	for i, f := range fields {
+		if err := cronFields[i].validate(f); err != nil {
+			return maskf(InvalidJobDefinitionError, "invalid schedule '%s': %s", schedule, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// validate checks a single field of a cron expression. A field is a comma
+// separated list of "*", numbers or ranges ("1-5"), each optionally
+// followed by a step ("*/15").
+func (cf cronField) validate(field string) error {
+	for _, part := range strings.Split(field, ",") {
+		rng := part
+		if i := strings.Index(part, "/"); i >= 0 {
+			rng = part[:i]
+			step, err := strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return maskf(InvalidJobDefinitionError, "invalid step '%s' in %s", part[i+1:], cf.name)
+			}
+		}
+
+		if rng == "*" {
+			continue
+		}
+
+		bounds := strings.SplitN(rng, "-", 2)
+		values := []int{}
+		for _, b := range bounds {
+			v, err := strconv.Atoi(b)
+			if err != nil {
+				return maskf(InvalidJobDefinitionError, "invalid value '%s' in %s", b, cf.name)
+			}
+			if v < cf.min || v > cf.max {
+				return maskf(InvalidJobDefinitionError, "value '%d' in %s must be between %d and %d", v, cf.name, cf.min, cf.max)
+			}
+			values = append(values, v)
+		}
+		if len(values) == 2 && values[0] > values[1] {
+			return maskf(InvalidJobDefinitionError, "invalid range '%s' in %s", rng, cf.name)
+		}
+	}
+
+	return nil
+}
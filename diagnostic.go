@@ -0,0 +1,246 @@
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diagnosticCodes maps the causes of diagnostics to their codes. Callers
+// rely on the codes, so they are given here instead of being derived from
+// the messages of the causes.
+var diagnosticCodes = map[error]string{
+	UnknownJSONFieldError:                     "unknown-json-field",
+	MissingJSONFieldError:                     "missing-json-field",
+	InvalidSizeError:                          "invalid-size",
+	DuplicateVolumePathError:                  "duplicate-volume-path",
+	InvalidEnvListFormatError:                 "invalid-env-list-format",
+	CrossServicePodError:                      "cross-service-pod",
+	PodUsedOnlyOnceError:                      "pod-used-only-once",
+	InvalidVolumeConfigError:                  "invalid-volume-configuration",
+	InvalidDependencyConfigError:              "invalid-dependency-configuration",
+	InvalidScalingConfigError:                 "invalid-scaling-configuration",
+	InvalidPortConfigError:                    "invalid-port-configuration",
+	InvalidDomainDefinitionError:              "invalid-domain-definition",
+	DuplicateDomainError:                      "duplicate-domain",
+	InvalidLinkDefinitionError:                "invalid-link-definition",
+	InvalidAppDefinitionError:                 "invalid-service-definition",
+	InvalidComponentDefinitionError:           "invalid-component-definition",
+	InvalidImageDefinitionError:               "invalid-image-definition",
+	InvalidServiceNameError:                   "invalid-service-name",
+	InvalidComponentNameError:                 "invalid-component-name",
+	InvalidPodConfigError:                     "invalid-pod-configuration",
+	PortNotFoundError:                         "port-not-found",
+	ComponentNotFoundError:                    "component-not-found",
+	InternalError:                             "internal-error",
+	MissingValidationContextError:             "missing-validation-context",
+	InvalidArgumentError:                      "invalid-argument",
+	VolumeCycleError:                          "volume-cycle",
+	WrongDiffOrderError:                       "wrong-diff-order",
+	LinkCycleError:                            "link-cycle",
+	InvalidMemoryLimitError:                   "invalid-memory-limit",
+	InvalidMemoryRequestError:                 "invalid-memory-request",
+	InvalidMemorySwapError:                    "invalid-memory-swap",
+	InvalidHealthCheckDefinitionError:         "invalid-health-check-definition",
+	InvalidSecretDefinitionError:              "invalid-secret-definition",
+	InvalidFileDefinitionError:                "invalid-file-definition",
+	InvalidRestartPolicyError:                 "invalid-restart-policy",
+	InvalidStopSignalError:                    "invalid-stop-signal",
+	InvalidStopTimeoutError:                   "invalid-stop-timeout",
+	InvalidJobDefinitionError:                 "invalid-job-definition",
+	InvalidUpdateStrategyError:                "invalid-update-strategy",
+	ImageDigestNotFoundError:                  "image-digest-not-found",
+	InvalidCPULimitError:                      "invalid-cpu-limit",
+	InvalidCPURequestError:                    "invalid-cpu-request",
+	UnknownByteSizeUnitError:                  "unknown-byte-size-unit",
+	InvalidByteSizeFormatNoDigitsError:        "invalid-byte-size-format",
+	InvalidByteSizeFormatUnexpectedTokenError: "invalid-byte-size-format",
+	InvalidCPUSizeFormatError:                 "invalid-cpu-size-format",
+	InvalidDurationFormatError:                "invalid-duration-format",
+}
+
+// causeParents lists causes that are refinements of other causes. A
+// diagnostic with such a cause also matches the parent in errors.Is, like
+// IsInvalidDomainDefinition matches DuplicateDomainError.
+var causeParents = map[error]error{
+	DuplicateDomainError: InvalidDomainDefinitionError,
+}
+
+// Diagnostic describes why a service definition is invalid, so callers do not
+// have to parse error messages. Validators return it as error, use
+// DiagnosticOf or errors.As to get it. The IsXxx helpers, errgo.Cause and
+// errors.Is match the cause of a diagnostic, e.g. InvalidLinkDefinitionError.
+type Diagnostic struct {
+	// Code of the problem, given by the cause, e.g. "invalid-link-definition".
+	// Codes do not change with the messages.
+	Code string
+
+	// Component the problem was found in, if any.
+	Component ComponentName
+
+	// Path of the offending field within the component, or the service
+	// definition if there is no component, e.g. "links[0]" or
+	// `domains["example.com"]`.
+	Field string
+
+	// Offending value, if any, e.g. "8080/tcp".
+	Value string
+
+	// Human readable description of the problem.
+	Message string
+
+	// Suggestion how to fix the problem, if any.
+	Fix string
+
+	cause      error
+	underlying error
+
+	// Path of an unknown JSON field and the names of the fields known there,
+	// so enclosing decoders can prefix the path, see decodeStrict.
+	jsonPath  string
+	jsonNames []string
+}
+
+// newDiagnostic returns a Diagnostic with the given cause, e.g.
+// InvalidLinkDefinitionError, and a message formatted like errgo.WithCausef
+// does.
+func newDiagnostic(cause error, f string, a ...interface{}) *Diagnostic {
+	msg := f
+	if len(a) > 0 {
+		msg = fmt.Sprintf(f, a...)
+	}
+	return &Diagnostic{
+		Code:    diagnosticCode(cause),
+		Message: msg,
+		cause:   cause,
+	}
+}
+
+// diagnosticCode returns the code of a diagnostic with the given cause, or
+// an empty string if the cause has none.
+func diagnosticCode(cause error) string {
+	return diagnosticCodes[cause]
+}
+
+func (d *Diagnostic) Error() string {
+	if d.Message == "" && d.underlying != nil {
+		return d.underlying.Error()
+	}
+	if d.Message == "" && d.cause != nil {
+		return d.cause.Error()
+	}
+	return d.Message
+}
+
+// Cause returns the cause of this diagnostic, e.g. InvalidLinkDefinitionError.
+// It lets errgo.Cause and the IsXxx helpers work on diagnostics.
+func (d *Diagnostic) Cause() error {
+	return d.cause
+}
+
+// Underlying returns the error this diagnostic was derived from, if any,
+// e.g. an ImagePolicyViolation.
+func (d *Diagnostic) Underlying() error {
+	return d.underlying
+}
+
+// Unwrap is the same as Underlying, for use with errors.As.
+func (d *Diagnostic) Unwrap() error {
+	return d.underlying
+}
+
+// Is returns true if the given target is the cause of this diagnostic, for
+// use with errors.Is.
+func (d *Diagnostic) Is(target error) bool {
+	for cause := d.cause; cause != nil; cause = causeParents[cause] {
+		if cause == target {
+			return true
+		}
+	}
+	return false
+}
+
+// withComponent sets the component of this diagnostic.
+func (d *Diagnostic) withComponent(componentName ComponentName) *Diagnostic {
+	d.Component = componentName
+	return d
+}
+
+// withValue sets the offending value of this diagnostic.
+func (d *Diagnostic) withValue(value interface{}) *Diagnostic {
+	d.Value = fmt.Sprint(value)
+	return d
+}
+
+// withFix sets the suggested fix of this diagnostic.
+func (d *Diagnostic) withFix(f string, a ...interface{}) *Diagnostic {
+	d.Fix = fmt.Sprintf(f, a...)
+	return d
+}
+
+// withField sets the path of the offending field of this diagnostic.
+func (d *Diagnostic) withField(field string) *Diagnostic {
+	d.Field = field
+	return d
+}
+
+// withUnderlying sets the error this diagnostic was derived from.
+func (d *Diagnostic) withUnderlying(err error) *Diagnostic {
+	d.underlying = err
+	return d
+}
+
+// DiagnosticOf returns the diagnostic the given error is, or was derived
+// from, if any.
+func DiagnosticOf(err error) (*Diagnostic, bool) {
+	for err != nil {
+		if d, ok := err.(*Diagnostic); ok {
+			return d, true
+		}
+		w, ok := err.(interface {
+			Underlying() error
+		})
+		if !ok {
+			return nil, false
+		}
+		err = w.Underlying()
+	}
+	return nil, false
+}
+
+// diagnosticInComponent sets the component of the diagnostic of the given
+// error, unless it is set already.
+func diagnosticInComponent(err error, componentName ComponentName) error {
+	if d, ok := DiagnosticOf(err); ok && d.Component.Empty() {
+		d.Component = componentName
+	}
+	return err
+}
+
+// diagnosticInField prefixes the field path of the diagnostic of the given
+// error with the given field, e.g. "links" and "[0]" become "links[0]".
+func diagnosticInField(err error, field string) error {
+	d, ok := DiagnosticOf(err)
+	if !ok {
+		return err
+	}
+	switch {
+	case d.Field == "":
+		d.Field = field
+	case strings.HasPrefix(d.Field, "["):
+		d.Field = field + d.Field
+	default:
+		d.Field = field + "." + d.Field
+	}
+	return err
+}
+
+// keepDiagnostic returns the given mask function, except that diagnostics
+// are returned as they are, so errors.As finds them.
+func keepDiagnostic(m func(error, ...func(error) bool) error) func(error, ...func(error) bool) error {
+	return func(err error, allow ...func(error) bool) error {
+		if d, ok := err.(*Diagnostic); ok {
+			return d
+		}
+		return m(err, allow...)
+	}
+}
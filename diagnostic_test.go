@@ -0,0 +1,168 @@
+package userconfig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/giantswarm/generic-types-go"
+	"github.com/giantswarm/user-config"
+)
+
+func TestDiagnostics(t *testing.T) {
+	list := []struct {
+		Modify    func(def *userconfig.ServiceDefinition)
+		Cause     error
+		Code      string
+		Component userconfig.ComponentName
+		Field     string
+		Value     string
+		Fix       bool
+	}{
+		// Link to a port that is not exported
+		{
+			func(def *userconfig.ServiceDefinition) {
+				def.Components["component/a"].Links = userconfig.LinkDefinitions{
+					{Component: "component/b", TargetPort: generictypes.MustParseDockerPort("5432/tcp")},
+				}
+			},
+			userconfig.InvalidComponentDefinitionError, "invalid-component-definition",
+			"component/a", "links[0]", "5432/tcp", true,
+		},
+		// Domain bound to a port that is not exported
+		{
+			func(def *userconfig.ServiceDefinition) {
+				def.Components["component/a"].Domains = userconfig.V2DomainDefinitions{
+					"api.example.com": {generictypes.MustParseDockerPort("8080/tcp")},
+				}
+			},
+			userconfig.InvalidDomainDefinitionError, "invalid-domain-definition",
+			"component/a", `domains["api.example.com"].port`, "8080/tcp", true,
+		},
+		// Memory request above the limit
+		{
+			func(def *userconfig.ServiceDefinition) {
+				def.Components["component/b"].MemoryLimit = "128M"
+				def.Components["component/b"].MemoryRequest = "256M"
+			},
+			userconfig.InvalidMemoryRequestError, "invalid-memory-request",
+			"component/b", "memory-request", "256M", true,
+		},
+		// Duplicate link name
+		{
+			func(def *userconfig.ServiceDefinition) {
+				port := generictypes.MustParseDockerPort("80/tcp")
+				def.Components["component/a"].Links = userconfig.LinkDefinitions{
+					{Component: "component/b", Alias: "b", TargetPort: port},
+					{Service: "other", Alias: "b", TargetPort: port},
+				}
+			},
+			userconfig.InvalidLinkDefinitionError, "invalid-link-definition",
+			"component/a", "links[1]", "b", true,
+		},
+		// Autoscale target out of range
+		{
+			func(def *userconfig.ServiceDefinition) {
+				def.Components["component/b"].Scale = &userconfig.ScaleDefinition{
+					Autoscale: &userconfig.AutoscaleDefinition{TargetCPU: 150},
+				}
+			},
+			userconfig.InvalidScalingConfigError, "invalid-scaling-configuration",
+			"component/b", "scale.autoscale.target-cpu", "150", false,
+		},
+		// Duplicate node label
+		{
+			func(def *userconfig.ServiceDefinition) {
+				def.Components["component/b"].Scale = &userconfig.ScaleDefinition{
+					Constraints: &userconfig.PlacementConstraints{NodeLabels: []string{"disk=ssd", "disk=hdd"}},
+				}
+			},
+			userconfig.InvalidScalingConfigError, "invalid-scaling-configuration",
+			"component/b", "scale.constraints.node-labels[1]", "disk=hdd", false,
+		},
+	}
+
+	for i, test := range list {
+		def := ExampleDefinition()
+		test.Modify(&def)
+
+		err := def.Validate(nil)
+		if err == nil {
+			t.Fatalf("Test %d: expected validation to fail", i)
+		}
+
+		var d *userconfig.Diagnostic
+		if !errors.As(err, &d) {
+			t.Fatalf("Test %d: expected diagnostic, got %#v", i, err)
+		}
+		if !errors.Is(err, test.Cause) {
+			t.Fatalf("Test %d: expected errors.Is to match cause '%v'", i, test.Cause)
+		}
+		if d.Code != test.Code {
+			t.Fatalf("Test %d: expected code '%s', got '%s'", i, test.Code, d.Code)
+		}
+		if d.Component != test.Component {
+			t.Fatalf("Test %d: expected component '%s', got '%s'", i, test.Component, d.Component)
+		}
+		if d.Field != test.Field {
+			t.Fatalf("Test %d: expected field '%s', got '%s'", i, test.Field, d.Field)
+		}
+		if d.Value != test.Value {
+			t.Fatalf("Test %d: expected value '%s', got '%s'", i, test.Value, d.Value)
+		}
+		if test.Fix && d.Fix == "" {
+			t.Fatalf("Test %d: expected a suggested fix", i)
+		}
+		if d.Message != err.Error() {
+			t.Fatalf("Test %d: expected message '%s', got '%s'", i, err.Error(), d.Message)
+		}
+	}
+}
+
+func TestDiagnosticKeepsIsHelpers(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/a"].Domains = userconfig.V2DomainDefinitions{
+		"api.example.com": {generictypes.MustParseDockerPort("80/tcp")},
+	}
+	def.Components["component/b"].Domains = userconfig.V2DomainDefinitions{
+		"api.example.com": {generictypes.MustParseDockerPort("80/tcp")},
+	}
+
+	err := def.Validate(nil)
+	if !userconfig.IsDuplicateDomain(err) || !userconfig.IsInvalidDomainDefinition(err) {
+		t.Fatalf("expected DuplicateDomainError, got %#v", err)
+	}
+	if !errors.Is(err, userconfig.DuplicateDomainError) || !errors.Is(err, userconfig.InvalidDomainDefinitionError) {
+		t.Fatalf("expected errors.Is to match DuplicateDomainError and InvalidDomainDefinitionError")
+	}
+	if errors.Is(err, userconfig.InvalidLinkDefinitionError) {
+		t.Fatalf("expected errors.Is not to match InvalidLinkDefinitionError")
+	}
+	d, ok := userconfig.DiagnosticOf(err)
+	if !ok {
+		t.Fatalf("expected diagnostic, got %#v", err)
+	}
+	if d.Field != `domains["api.example.com"].paths` || d.Value != "/" {
+		t.Fatalf("unexpected diagnostic: %#v", d)
+	}
+}
+
+func TestDiagnosticOfPolicyViolation(t *testing.T) {
+	def := ExampleDefinition()
+	valCtx := NewValidationContext()
+	valCtx.RegistryPolicies = []userconfig.RegistryPolicy{
+		{Name: "giantswarm-only", Registry: "registry.giantswarm.io", Namespaces: []string{"giantswarm"}},
+	}
+
+	err := def.Validate(valCtx)
+	var v *userconfig.ImagePolicyViolation
+	if !errors.As(err, &v) || v.Policy != "giantswarm-only" {
+		t.Fatalf("expected violation of policy 'giantswarm-only', got %#v", err)
+	}
+	d, ok := userconfig.DiagnosticOf(err)
+	if !ok {
+		t.Fatalf("expected diagnostic, got %#v", err)
+	}
+	if d.Field != "image" || d.Value != v.Image || d.Component.Empty() {
+		t.Fatalf("unexpected diagnostic: %#v", d)
+	}
+}
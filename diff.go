@@ -28,12 +28,21 @@ const (
 	// DiffTypeComponentPortsUpdated
 	DiffTypeComponentPortsUpdated DiffType = "component-ports-updated"
 
+	// DiffTypeComponentPortNamesUpdated
+	DiffTypeComponentPortNamesUpdated DiffType = "component-port-names-updated"
+
 	// DiffTypeComponentEnvUpdated
 	DiffTypeComponentEnvUpdated DiffType = "component-env-updated"
 
+	// DiffTypeComponentSecretsUpdated
+	DiffTypeComponentSecretsUpdated DiffType = "component-secrets-updated"
+
 	// DiffTypeComponentVolumesUpdated
 	DiffTypeComponentVolumesUpdated DiffType = "component-volumes-updated"
 
+	// DiffTypeComponentFilesUpdated
+	DiffTypeComponentFilesUpdated DiffType = "component-files-updated"
+
 	// DiffTypeComponentArgsUpdated
 	DiffTypeComponentArgsUpdated DiffType = "component-args-updated"
 
@@ -55,6 +64,12 @@ const (
 	// DiffTypeComponentScaleMaxUpdated
 	DiffTypeComponentScaleMaxUpdated DiffType = "component-scale-max-updated"
 
+	// DiffTypeComponentScaleConstraintsUpdated
+	DiffTypeComponentScaleConstraintsUpdated DiffType = "component-scale-constraints-updated"
+
+	// DiffTypeComponentScaleAutoscaleUpdated
+	DiffTypeComponentScaleAutoscaleUpdated DiffType = "component-scale-autoscale-updated"
+
 	// DiffTypeComponentPodUpdated
 	DiffTypeComponentPodUpdated DiffType = "component-pod-updated"
 
@@ -63,8 +78,63 @@ const (
 
 	// DiffTypeComponentMemoryLimitUpdated
 	DiffTypeComponentMemoryLimitUpdated DiffType = "component-memory-limit-updated"
+
+	// DiffTypeComponentMemoryRequestUpdated
+	DiffTypeComponentMemoryRequestUpdated DiffType = "component-memory-request-updated"
+
+	// DiffTypeComponentMemorySwapUpdated
+	DiffTypeComponentMemorySwapUpdated DiffType = "component-memory-swap-updated"
+
+	// DiffTypeComponentCPULimitUpdated
+	DiffTypeComponentCPULimitUpdated DiffType = "component-cpu-limit-updated"
+
+	// DiffTypeComponentCPURequestUpdated
+	DiffTypeComponentCPURequestUpdated DiffType = "component-cpu-request-updated"
+
+	// DiffTypeComponentHealthCheckUpdated
+	DiffTypeComponentHealthCheckUpdated DiffType = "component-healthcheck-updated"
+
+	// DiffTypeComponentRestartUpdated
+	DiffTypeComponentRestartUpdated DiffType = "component-restart-updated"
+
+	// DiffTypeComponentStopSignalUpdated
+	DiffTypeComponentStopSignalUpdated DiffType = "component-stop-signal-updated"
+
+	// DiffTypeComponentStopTimeoutUpdated
+	DiffTypeComponentStopTimeoutUpdated DiffType = "component-stop-timeout-updated"
+
+	// DiffTypeComponentRunUpdated
+	DiffTypeComponentRunUpdated DiffType = "component-run-updated"
+
+	// DiffTypeComponentScheduleUpdated
+	DiffTypeComponentScheduleUpdated DiffType = "component-schedule-updated"
+
+	// DiffTypeComponentAfterUpdated
+	DiffTypeComponentAfterUpdated DiffType = "component-after-updated"
+
+	// DiffTypeComponentInitUpdated
+	DiffTypeComponentInitUpdated DiffType = "component-init-updated"
+
+	// DiffTypeComponentUpdateStrategyUpdated
+	DiffTypeComponentUpdateStrategyUpdated DiffType = "component-update-strategy-updated"
+
+	// DiffTypeComponentLintIgnoreUpdated
+	DiffTypeComponentLintIgnoreUpdated DiffType = "component-lint-ignore-updated"
 )
 
+// RequiresRestart returns true if a change of the given diff type requires
+// the affected component to be restarted. Changes of the update strategy for
+// example only affect the next rollout, port names are only used to refer to
+// ports within the definition and lint rules are not used at runtime at all.
+func (dt DiffType) RequiresRestart() bool {
+	switch dt {
+	case DiffTypeComponentUpdateStrategyUpdated, DiffTypeComponentPortNamesUpdated, DiffTypeComponentLintIgnoreUpdated:
+		return false
+	default:
+		return true
+	}
+}
+
 type DiffInfo struct {
 	Type DiffType
 
@@ -102,6 +172,20 @@ func (dis DiffInfos) ComponentNames() ComponentNames {
 	return componentNames
 }
 
+// RequiringRestart returns a copied list of diff infos, only containing the
+// diff types that require a restart of the affected component.
+func (dis DiffInfos) RequiringRestart() DiffInfos {
+	newDiffInfos := DiffInfos{}
+
+	for _, di := range dis {
+		if di.Type.RequiresRestart() {
+			newDiffInfos = append(newDiffInfos, di)
+		}
+	}
+
+	return newDiffInfos
+}
+
 // service diff
 
 // ServiceDiff checks the difference between two service definitions. The
@@ -224,8 +308,11 @@ func diffComponentUpdated(oldDefs, newDefs ComponentDefinitions) DiffInfos {
 //   - DiffTypeComponentImageUpdated
 //   - DiffTypeComponentEntrypointUpdated
 //   - DiffTypeComponentPortsUpdated
+//   - DiffTypeComponentPortNamesUpdated
 //   - DiffTypeComponentEnvUpdated
+//   - DiffTypeComponentSecretsUpdated
 //   - DiffTypeComponentVolumesUpdated
+//   - DiffTypeComponentFilesUpdated
 //   - DiffTypeComponentArgsUpdated
 //   - DiffTypeComponentDomainsUpdated
 //   - DiffTypeComponentLinksUpdated
@@ -233,17 +320,36 @@ func diffComponentUpdated(oldDefs, newDefs ComponentDefinitions) DiffInfos {
 //   - DiffTypeComponentScalePlacementUpdated
 //   - DiffTypeComponentScaleMinUpdated
 //   - DiffTypeComponentScaleMaxUpdated
+//   - DiffTypeComponentScaleConstraintsUpdated
+//   - DiffTypeComponentScaleAutoscaleUpdated
 //   - DiffTypeComponentPodUpdated
 //   - DiffTypeComponentSignalReadyUpdated
 //   - DiffTypeComponentMemoryLimitUpdated
+//   - DiffTypeComponentMemoryRequestUpdated
+//   - DiffTypeComponentMemorySwapUpdated
+//   - DiffTypeComponentCPULimitUpdated
+//   - DiffTypeComponentCPURequestUpdated
+//   - DiffTypeComponentHealthCheckUpdated
+//   - DiffTypeComponentRestartUpdated
+//   - DiffTypeComponentStopSignalUpdated
+//   - DiffTypeComponentStopTimeoutUpdated
+//   - DiffTypeComponentRunUpdated
+//   - DiffTypeComponentScheduleUpdated
+//   - DiffTypeComponentAfterUpdated
+//   - DiffTypeComponentInitUpdated
+//   - DiffTypeComponentUpdateStrategyUpdated
+//   - DiffTypeComponentLintIgnoreUpdated
 func ComponentDiff(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
 	diffInfos := DiffInfos{} // diff info tracked in detail
 
 	diffInfos = append(diffInfos, diffComponentImage(oldDef, newDef, componentName)...)
 	diffInfos = append(diffInfos, diffComponentEntrypoint(oldDef, newDef, componentName)...)
 	diffInfos = append(diffInfos, diffComponentPorts(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentPortNames(oldDef, newDef, componentName)...)
 	diffInfos = append(diffInfos, diffComponentEnv(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentSecrets(oldDef, newDef, componentName)...)
 	diffInfos = append(diffInfos, diffComponentVolumes(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentFiles(oldDef, newDef, componentName)...)
 	diffInfos = append(diffInfos, diffComponentArgs(oldDef, newDef, componentName)...)
 	diffInfos = append(diffInfos, diffComponentDomains(oldDef, newDef, componentName)...)
 	diffInfos = append(diffInfos, diffComponentLinks(oldDef, newDef, componentName)...)
@@ -252,6 +358,16 @@ func ComponentDiff(oldDef, newDef ComponentDefinition, componentName ComponentNa
 	diffInfos = append(diffInfos, diffComponentSignalReady(oldDef, newDef, componentName)...)
 	diffInfos = append(diffInfos, diffComponentScale(oldDef, newDef, componentName)...)
 	diffInfos = append(diffInfos, diffComponentMemoryLimit(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentMemoryRequest(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentMemorySwap(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentCPU(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentHealthCheck(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentRestart(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentStop(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentJob(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentInit(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentUpdateStrategy(oldDef, newDef, componentName)...)
+	diffInfos = append(diffInfos, diffComponentLintIgnore(oldDef, newDef, componentName)...)
 
 	return diffInfos
 }
@@ -269,6 +385,77 @@ func diffComponentMemoryLimit(oldDef, newDef ComponentDefinition, componentName
 	return DiffInfos{}
 }
 
+func diffComponentMemoryRequest(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	if oldDef.MemoryRequest != newDef.MemoryRequest {
+		return DiffInfos{DiffInfo{
+			Type:      DiffTypeComponentMemoryRequestUpdated,
+			Key:       "memory-request",
+			Component: componentName,
+			Old:       oldDef.MemoryRequest.String(),
+			New:       newDef.MemoryRequest.String(),
+		}}
+	}
+	return DiffInfos{}
+}
+
+func diffComponentMemorySwap(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	if oldDef.MemorySwap != newDef.MemorySwap {
+		return DiffInfos{DiffInfo{
+			Type:      DiffTypeComponentMemorySwapUpdated,
+			Key:       "memory-swap",
+			Component: componentName,
+			Old:       oldDef.MemorySwap.String(),
+			New:       newDef.MemorySwap.String(),
+		}}
+	}
+	return DiffInfos{}
+}
+
+func diffComponentCPU(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	diffInfos := DiffInfos{}
+
+	if oldDef.CPULimit != newDef.CPULimit {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentCPULimitUpdated,
+			Key:       "cpu-limit",
+			Component: componentName,
+			Old:       oldDef.CPULimit.String(),
+			New:       newDef.CPULimit.String(),
+		})
+	}
+
+	if oldDef.CPURequest != newDef.CPURequest {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentCPURequestUpdated,
+			Key:       "cpu-request",
+			Component: componentName,
+			Old:       oldDef.CPURequest.String(),
+			New:       newDef.CPURequest.String(),
+		})
+	}
+
+	return diffInfos
+}
+
+func diffComponentHealthCheck(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	diffInfos := DiffInfos{}
+
+	oldHealthCheck := oldDef.HealthCheck.String()
+	newHealthCheck := newDef.HealthCheck.String()
+
+	if oldHealthCheck != newHealthCheck {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentHealthCheckUpdated,
+			Key:       "healthcheck",
+			Component: componentName,
+			Old:       oldHealthCheck,
+			New:       newHealthCheck,
+		})
+	}
+
+	return diffInfos
+}
+
 func diffComponentImage(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
 	diffInfos := DiffInfos{}
 
@@ -350,6 +537,27 @@ func diffComponentEnv(oldDef, newDef ComponentDefinition, componentName Componen
 	return diffInfos
 }
 
+// diffComponentSecrets only compares the secret references. Secret values are
+// not part of the definition, so they can never leak into a diff.
+func diffComponentSecrets(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	diffInfos := DiffInfos{}
+
+	oldSecrets := oldDef.Secrets.String()
+	newSecrets := newDef.Secrets.String()
+
+	if oldSecrets != newSecrets {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentSecretsUpdated,
+			Key:       "secrets",
+			Component: componentName,
+			Old:       oldSecrets,
+			New:       newSecrets,
+		})
+	}
+
+	return diffInfos
+}
+
 func diffComponentVolumes(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
 	diffInfos := DiffInfos{}
 
@@ -370,6 +578,29 @@ func diffComponentVolumes(oldDef, newDef ComponentDefinition, componentName Comp
 	return diffInfos
 }
 
+// diffComponentFiles compares files by path, source, mode and content hash.
+// The content itself does not show up in the diff. Files given by source are
+// compared by their source only, use ServiceDefinition.InlineFiles on both
+// definitions to compare their content.
+func diffComponentFiles(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	diffInfos := DiffInfos{}
+
+	oldFiles := oldDef.Files.String()
+	newFiles := newDef.Files.String()
+
+	if oldFiles != newFiles {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentFilesUpdated,
+			Key:       "files",
+			Component: componentName,
+			Old:       oldFiles,
+			New:       newFiles,
+		})
+	}
+
+	return diffInfos
+}
+
 func diffComponentArgs(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
 	diffInfos := DiffInfos{}
 
@@ -394,8 +625,8 @@ func diffComponentDomains(oldDef, newDef ComponentDefinition, componentName Comp
 	diffInfos := DiffInfos{}
 
 	// TODO this needs to be more fine grained
-	oldDomains := oldDef.Domains.String()
-	newDomains := newDef.Domains.String()
+	oldDomains := domainsString(oldDef)
+	newDomains := domainsString(newDef)
 
 	if oldDomains != newDomains {
 		diffInfos = append(diffInfos, DiffInfo{
@@ -410,6 +641,15 @@ func diffComponentDomains(oldDef, newDef ComponentDefinition, componentName Comp
 	return diffInfos
 }
 
+// domainsString returns the string represantion of the domains of the given
+// definition, followed by their settings, if any.
+func domainsString(def ComponentDefinition) string {
+	if len(def.DomainSettings) == 0 {
+		return def.Domains.String()
+	}
+	return def.Domains.String() + " " + def.DomainSettings.String()
+}
+
 func diffComponentLinks(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
 	diffInfos := DiffInfos{}
 
@@ -497,6 +737,26 @@ func diffComponentScale(oldDef, newDef ComponentDefinition, componentName Compon
 		})
 	}
 
+	if !oldScaleDef.Constraints.Equals(newScaleDef.Constraints) {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentScaleConstraintsUpdated,
+			Key:       "scale.constraints",
+			Component: componentName,
+			Old:       oldScaleDef.Constraints.String(),
+			New:       newScaleDef.Constraints.String(),
+		})
+	}
+
+	if !oldScaleDef.Autoscale.Equals(newScaleDef.Autoscale) {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentScaleAutoscaleUpdated,
+			Key:       "scale.autoscale",
+			Component: componentName,
+			Old:       oldScaleDef.Autoscale.String(),
+			New:       newScaleDef.Autoscale.String(),
+		})
+	}
+
 	return diffInfos
 }
 
@@ -556,3 +816,155 @@ func orderedComponentKeys(defs ComponentDefinitions) []string {
 func isDefaultPlacement(oldPlacement, newPlacement Placement) bool {
 	return ((oldPlacement == "" || oldPlacement == DefaultPlacement) && (newPlacement == "" || newPlacement == DefaultPlacement))
 }
+
+func diffComponentRestart(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	diffInfos := DiffInfos{}
+
+	if !oldDef.Restart.Equals(newDef.Restart) {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentRestartUpdated,
+			Key:       "restart",
+			Component: componentName,
+			Old:       oldDef.Restart.String(),
+			New:       newDef.Restart.String(),
+		})
+	}
+
+	return diffInfos
+}
+
+func diffComponentStop(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	diffInfos := DiffInfos{}
+
+	if oldDef.StopSignal != newDef.StopSignal {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentStopSignalUpdated,
+			Key:       "stop-signal",
+			Component: componentName,
+			Old:       oldDef.StopSignal,
+			New:       newDef.StopSignal,
+		})
+	}
+
+	if oldDef.StopTimeout != newDef.StopTimeout {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentStopTimeoutUpdated,
+			Key:       "stop-timeout",
+			Component: componentName,
+			Old:       oldDef.StopTimeout.String(),
+			New:       newDef.StopTimeout.String(),
+		})
+	}
+
+	return diffInfos
+}
+
+func diffComponentJob(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	diffInfos := DiffInfos{}
+
+	if oldDef.Run != newDef.Run {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentRunUpdated,
+			Key:       "run",
+			Component: componentName,
+			Old:       oldDef.Run.String(),
+			New:       newDef.Run.String(),
+		})
+	}
+
+	if oldDef.Schedule != newDef.Schedule {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentScheduleUpdated,
+			Key:       "schedule",
+			Component: componentName,
+			Old:       oldDef.Schedule,
+			New:       newDef.Schedule,
+		})
+	}
+
+	oldAfter := oldDef.After.ToJSONString()
+	newAfter := newDef.After.ToJSONString()
+
+	if oldAfter != newAfter {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentAfterUpdated,
+			Key:       "after",
+			Component: componentName,
+			Old:       oldAfter,
+			New:       newAfter,
+		})
+	}
+
+	return diffInfos
+}
+
+func diffComponentInit(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	if oldDef.Init != newDef.Init {
+		return DiffInfos{DiffInfo{
+			Type:      DiffTypeComponentInitUpdated,
+			Key:       "init",
+			Component: componentName,
+			Old:       strconv.Itoa(oldDef.Init),
+			New:       strconv.Itoa(newDef.Init),
+		}}
+	}
+
+	return DiffInfos{}
+}
+
+func diffComponentUpdateStrategy(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	diffInfos := DiffInfos{}
+
+	oldUpdate := oldDef.Update.String()
+	newUpdate := newDef.Update.String()
+
+	if oldUpdate != newUpdate {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentUpdateStrategyUpdated,
+			Key:       "update",
+			Component: componentName,
+			Old:       oldUpdate,
+			New:       newUpdate,
+		})
+	}
+
+	return diffInfos
+}
+
+func diffComponentPortNames(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	diffInfos := DiffInfos{}
+
+	oldNames := oldDef.PortNames.String()
+	newNames := newDef.PortNames.String()
+
+	if oldNames != newNames {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentPortNamesUpdated,
+			Key:       "ports",
+			Component: componentName,
+			Old:       oldNames,
+			New:       newNames,
+		})
+	}
+
+	return diffInfos
+}
+
+func diffComponentLintIgnore(oldDef, newDef ComponentDefinition, componentName ComponentName) DiffInfos {
+	diffInfos := DiffInfos{}
+
+	oldIgnored := strings.Join(oldDef.lintIgnored(), ",")
+	newIgnored := strings.Join(newDef.lintIgnored(), ",")
+
+	if oldIgnored != newIgnored {
+		diffInfos = append(diffInfos, DiffInfo{
+			Type:      DiffTypeComponentLintIgnoreUpdated,
+			Key:       "x-lint-ignore",
+			Component: componentName,
+			Old:       oldIgnored,
+			New:       newIgnored,
+		})
+	}
+
+	return diffInfos
+}
@@ -185,6 +185,101 @@ func TestDiffComponentMemoryLimitUpdated__Added(t *testing.T) {
 	testDiffCallWith(t, oldDef, newDef, expectedDiffInfos)
 }
 
+func TestDiffComponentMemoryRequestAndSwapUpdated(t *testing.T) {
+	oldDef := ExampleDefinition()
+	oldDef.Components[ComponentName("my-component")] = &ComponentDefinition{
+		Image:         MustParseImageDefinition("registry.giantswarm.io/landingpage:0.10.0"),
+		MemoryRequest: ByteSize("512 mb"),
+	}
+	newDef := ExampleDefinition()
+	newDef.Components[ComponentName("my-component")] = &ComponentDefinition{
+		Image:         MustParseImageDefinition("registry.giantswarm.io/landingpage:0.10.0"),
+		MemoryRequest: ByteSize("1 gb"),
+		MemorySwap:    ByteSize("256 mb"),
+	}
+
+	expectedDiffInfos := DiffInfos{
+		DiffInfo{
+			Type:      DiffTypeComponentMemoryRequestUpdated,
+			Component: "my-component",
+			Key:       "memory-request",
+			Old:       "512 mb",
+			New:       "1 gb",
+		},
+		DiffInfo{
+			Type:      DiffTypeComponentMemorySwapUpdated,
+			Component: "my-component",
+			Key:       "memory-swap",
+			Old:       "",
+			New:       "256 mb",
+		},
+	}
+
+	testDiffCallWith(t, oldDef, newDef, expectedDiffInfos)
+}
+
+func TestDiffComponentCPUUpdated(t *testing.T) {
+	oldDef := ExampleDefinition()
+	oldDef.Components[ComponentName("my-component")] = &ComponentDefinition{
+		Image:    MustParseImageDefinition("registry.giantswarm.io/landingpage:0.10.0"),
+		CPULimit: CPUSize("1"),
+	}
+	newDef := ExampleDefinition()
+	newDef.Components[ComponentName("my-component")] = &ComponentDefinition{
+		Image:      MustParseImageDefinition("registry.giantswarm.io/landingpage:0.10.0"),
+		CPULimit:   CPUSize("2"),
+		CPURequest: CPUSize("500m"),
+	}
+
+	expectedDiffInfos := DiffInfos{
+		DiffInfo{
+			Type:      DiffTypeComponentCPULimitUpdated,
+			Component: "my-component",
+			Key:       "cpu-limit",
+			Old:       "1",
+			New:       "2",
+		},
+		DiffInfo{
+			Type:      DiffTypeComponentCPURequestUpdated,
+			Component: "my-component",
+			Key:       "cpu-request",
+			Old:       "",
+			New:       "500m",
+		},
+	}
+
+	testDiffCallWith(t, oldDef, newDef, expectedDiffInfos)
+}
+
+func TestDiffComponentHealthCheckUpdated(t *testing.T) {
+	oldDef := ExampleDefinition()
+	oldDef.Components[ComponentName("my-component")] = &ComponentDefinition{
+		Image: MustParseImageDefinition("registry.giantswarm.io/landingpage:0.10.0"),
+		Ports: []generictypes.DockerPort{generictypes.MustParseDockerPort("80/tcp")},
+	}
+	newDef := ExampleDefinition()
+	newDef.Components[ComponentName("my-component")] = &ComponentDefinition{
+		Image: MustParseImageDefinition("registry.giantswarm.io/landingpage:0.10.0"),
+		Ports: []generictypes.DockerPort{generictypes.MustParseDockerPort("80/tcp")},
+		HealthCheck: &HealthCheckDefinition{
+			TCP:      &TCPHealthCheck{Port: generictypes.MustParseDockerPort("80/tcp")},
+			Interval: "10s",
+		},
+	}
+
+	expectedDiffInfos := DiffInfos{
+		DiffInfo{
+			Type:      DiffTypeComponentHealthCheckUpdated,
+			Component: "my-component",
+			Key:       "healthcheck",
+			Old:       "",
+			New:       `{"tcp":{"port":"80/tcp"},"interval":"10s"}`,
+		},
+	}
+
+	testDiffCallWith(t, oldDef, newDef, expectedDiffInfos)
+}
+
 func TestDiffComponentAddedAndRemoved(t *testing.T) {
 	oldDef := ExampleDefinition()
 	oldDef.Components[ComponentName("my-old-component")] = &ComponentDefinition{
@@ -4,90 +4,265 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/giantswarm/generic-types-go"
 )
 
-type V2DomainDefinitions map[generictypes.Domain]PortDefinitions
-type domainList []generictypes.Domain
+// wildcardPrefix marks a domain that matches all direct subdomains, e.g.
+// "*.example.com".
+const wildcardPrefix = "*."
 
-// UnmarshalJSON performs custom unmarshalling to support smart
-// data types.
-func (dds *V2DomainDefinitions) UnmarshalJSON(data []byte) error {
-	// Try format 1: domain: port
-	err := dds.unmarshalJSONDomainPortMap(data)
+// TLSDefinition describes where the certificate for a domain comes from.
+type TLSDefinition struct {
+	// If true, a certificate is obtained automatically.
+	Auto bool `json:"auto,omitempty" description:"If true, a certificate is obtained automatically"`
+
+	// Name of a stored certificate to use.
+	Certificate string `json:"certificate,omitempty" description:"Name of a stored certificate to use"`
+}
+
+func (td *TLSDefinition) validate(domain generictypes.Domain) error {
+	if td.Auto && td.Certificate != "" {
+		return maskf(InvalidDomainDefinitionError, "tls of domain '%s' cannot use auto and certificate both", domain)
+	}
+	if !td.Auto && td.Certificate == "" {
+		return maskf(InvalidDomainDefinitionError, "tls of domain '%s' needs auto or certificate", domain)
+	}
+	if td.Auto && isWildcardDomain(domain) {
+		return maskf(InvalidDomainDefinitionError, "tls of wildcard domain '%s' needs a certificate", domain)
+	}
+	return nil
+}
+
+// DomainSettings describes how a domain is served, besides the ports it is
+// bound to.
+type DomainSettings struct {
+	TLS *TLSDefinition `json:"tls,omitempty" description:"TLS settings of the domain"`
+
+	// If true, HTTP requests are redirected to HTTPS. Requires TLS.
+	RedirectHTTPS bool `json:"redirect-https,omitempty" description:"If true, HTTP requests are redirected to HTTPS. Requires tls"`
+
+	// Path prefixes routed to this component, e.g. "/api". Defaults to "/".
+	Paths []string `json:"paths,omitempty" description:"Path prefixes routed to this component. Defaults to '/'"`
+
+	// Name of the port to bind the domain to, if it is referred to by name.
+	// Given as port, e.g. "http".
+	PortName PortName `json:"-"`
+}
+
+// isEmpty returns true if no settings are given, so the domain can be
+// expressed in the short formats.
+func (ds DomainSettings) isEmpty() bool {
+	return ds.TLS == nil && !ds.RedirectHTTPS && len(ds.Paths) == 0 && ds.PortName.Empty()
+}
+
+// V2DomainSettings maps domains to their settings, e.g. "www.heise.de" =>
+// TLS settings. Only domains that have settings are contained.
+type V2DomainSettings map[generictypes.Domain]DomainSettings
+
+// String returns the marshalled and ordered string represantion of its own
+// incarnation. It is important to have the string represantion ordered, since
+// we use it to compare two V2DomainSettings when creating a diff. See diff.go
+func (dss V2DomainSettings) String() string {
+	simple := map[string]interface{}{}
+	for domain, ds := range dss {
+		ordered := ds
+		ordered.Paths = append([]string{}, ds.Paths...)
+		sort.Strings(ordered.Paths)
+		simple[domain.String()] = domainDefinition{Settings: ordered}
+	}
+
+	raw, err := json.Marshal(simple)
+	if err != nil {
+		panic(fmt.Sprintf("%#v\n", mask(err)))
+	}
+
+	return string(raw)
+}
+
+// domainDefinition is the JSON representation of a single domain in the
+// format domain: definition, e.g. { "port": "http", "tls": ... }.
+type domainDefinition struct {
+	Ports    PortDefinitions
+	Settings DomainSettings
+}
+
+// UnmarshalJSON performs custom unmarshalling to support referring to the
+// port by name.
+func (dd *domainDefinition) UnmarshalJSON(data []byte) error {
+	var local struct {
+		DomainSettings
+		Ports json.RawMessage `json:"port"`
+	}
+	if err := decodeStrict(data, &local); err != nil {
+		return mask(err)
+	}
+
+	dd.Settings = local.DomainSettings
+	if len(local.Ports) > 0 {
+		if err := dd.unmarshalPort(local.Ports); err != nil {
+			return mask(err)
+		}
+	}
+
+	return nil
+}
+
+// unmarshalPort unmarshals the given ports or port name.
+func (dd *domainDefinition) unmarshalPort(data []byte) error {
+	var ports PortDefinitions
+	err := json.Unmarshal(data, &ports)
 	if err == nil {
-		// Found a correct result
+		dd.Ports = ports
 		return nil
 	}
 
-	// Try format 2: port: domainList
-	if err2 := dds.unmarshalJSONPortDomainList(data); err2 == nil {
-		// Found a correct result
+	if _, name, nameErr := parsePortOrName(data); nameErr == nil && !name.Empty() {
+		dd.Settings.PortName = name
 		return nil
 	}
 
-	// Unknown format
-	return maskf(InvalidDomainDefinitionError, "invalid format for domains: %s", err.Error())
+	return mask(err)
+}
+
+// MarshalJSON performs custom marshalling to keep the port name, if any.
+func (dd domainDefinition) MarshalJSON() ([]byte, error) {
+	local := struct {
+		Ports interface{} `json:"port"`
+		DomainSettings
+	}{
+		Ports:          dd.Ports,
+		DomainSettings: dd.Settings,
+	}
+	if !dd.Settings.PortName.Empty() {
+		local.Ports = dd.Settings.PortName
+	}
+
+	data, err := json.Marshal(local)
+	if err != nil {
+		return nil, mask(err)
+	}
+	return data, nil
+}
+
+// domainDefinitions is the JSON representation of the domains of a
+// component, together with their settings. The following formats are
+// supported, also mixed:
+//   - domain: port
+//   - port: domainList
+//   - domain: { "port": port, "tls": ..., "redirect-https": ... }
+//
+// In the last format, the port may also be given by name, e.g. "http".
+type domainDefinitions struct {
+	Ports    V2DomainDefinitions
+	Settings V2DomainSettings
+}
+
+func (dds *domainDefinitions) UnmarshalJSON(data []byte) error {
+	var local map[string]json.RawMessage
+	if err := json.Unmarshal(data, &local); err != nil {
+		return newDiagnostic(InvalidDomainDefinitionError, "invalid format for domains: %s", err.Error()).withField("domains").withUnderlying(err)
+	}
+
+	dds.Ports = V2DomainDefinitions{}
+	dds.Settings = V2DomainSettings{}
+	for key, value := range local {
+		if port, err := generictypes.ParseDockerPort(key); err == nil {
+			// Format: port: domainList
+			var list domainList
+			if err := json.Unmarshal(value, &list); err != nil {
+				return newDiagnostic(InvalidDomainDefinitionError, "invalid format for domains: %s", err.Error()).withField(fmt.Sprintf("domains[%q]", key)).withValue(string(value)).withUnderlying(err)
+			}
+			for _, domain := range list {
+				dds.Ports[domain] = append(dds.Ports[domain], port)
+			}
+			continue
+		}
+
+		domain := generictypes.Domain(key)
+		if len(value) > 0 && value[0] == '{' {
+			// Format: domain: definition
+			var local domainDefinition
+			if err := json.Unmarshal(value, &local); err != nil {
+				if IsUnknownJsonField(err) {
+					return inJSONPath(err, fmt.Sprintf("[%q]", key))
+				}
+				return newDiagnostic(InvalidDomainDefinitionError, "invalid format for domains: %s", err.Error()).withField(fmt.Sprintf("domains[%q]", key)).withValue(string(value)).withUnderlying(err)
+			}
+			dds.Ports[domain] = append(dds.Ports[domain], local.Ports...)
+			if !local.Settings.isEmpty() {
+				dds.Settings[domain] = local.Settings
+			}
+		} else {
+			// Format: domain: port
+			var ports PortDefinitions
+			if err := json.Unmarshal(value, &ports); err != nil {
+				return newDiagnostic(InvalidDomainDefinitionError, "invalid format for domains: %s", err.Error()).withField(fmt.Sprintf("domains[%q]", key)).withValue(string(value)).withUnderlying(err)
+			}
+			dds.Ports[domain] = append(dds.Ports[domain], ports...)
+		}
+	}
+
+	if len(dds.Settings) == 0 {
+		dds.Settings = nil
+	}
+
+	return nil
 }
 
 // MarshalJSON performs custom marshalling to generate the reverse format:
-// port: domainList
-func (dds V2DomainDefinitions) MarshalJSON() ([]byte, error) {
-	portDomainMap := make(map[string]domainList)
-	for domain, ports := range dds {
+// port: domainList. Domains with settings are marshalled in the format
+// domain: definition.
+func (dds domainDefinitions) MarshalJSON() ([]byte, error) {
+	result := make(map[string]interface{})
+	for domain, ports := range dds.Ports {
+		if ds, ok := dds.Settings[domain]; ok {
+			result[domain.String()] = domainDefinition{Ports: ports, Settings: ds}
+			continue
+		}
 		for _, port := range ports {
 			portStr := port.String()
-			list, ok := portDomainMap[portStr]
+			list, ok := result[portStr].(domainList)
 			if !ok {
 				list = domainList{}
 			}
 			list = append(list, domain)
-			portDomainMap[portStr] = list
+			result[portStr] = list
 		}
 	}
 
-	data, err := json.Marshal(portDomainMap)
+	data, err := json.Marshal(result)
 	if err != nil {
 		return nil, mask(err)
 	}
 	return data, nil
 }
 
-// unmarshalJSONDomainPortMap tries to unmarshal the given data
-// in format: domain: port, domain2: port2
-func (dds *V2DomainDefinitions) unmarshalJSONDomainPortMap(data []byte) error {
-	var local map[generictypes.Domain]PortDefinitions
-	if err := json.Unmarshal(data, &local); err == nil {
-		// Found a correct result
-		*dds = V2DomainDefinitions(local)
-		return nil
-	} else {
+type V2DomainDefinitions map[generictypes.Domain]PortDefinitions
+type domainList []generictypes.Domain
+
+// UnmarshalJSON performs custom unmarshalling to support smart
+// data types. All formats of the domains of a component are supported,
+// settings are dropped though. See ComponentDefinition.DomainSettings.
+func (dds *V2DomainDefinitions) UnmarshalJSON(data []byte) error {
+	var local domainDefinitions
+	if err := json.Unmarshal(data, &local); err != nil {
 		return mask(err)
 	}
+
+	*dds = local.Ports
+	return nil
 }
 
-// unmarshalJSONPortDomainList tries to unmarshal the given data
-// in format: port: domainList, port2: domainList...
-func (dds *V2DomainDefinitions) unmarshalJSONPortDomainList(data []byte) error {
-	var local map[string]domainList
-	if err := json.Unmarshal(data, &local); err == nil {
-		// Found a correct result, convert it
-		newMap := V2DomainDefinitions{}
-		for p, list := range local {
-			port, err := generictypes.ParseDockerPort(p)
-			if err != nil {
-				return mask(err)
-			}
-			for _, domain := range list {
-				newMap[domain] = PortDefinitions{port}
-			}
-		}
-		*dds = newMap
-		return nil
-	} else {
-		return mask(err)
+// MarshalJSON performs custom marshalling to generate the reverse format:
+// port: domainList
+func (dds V2DomainDefinitions) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(domainDefinitions{Ports: dds})
+	if err != nil {
+		return nil, mask(err)
 	}
+	return data, nil
 }
 
 // UnmarshalJSON performs custom unmarshalling to support smart
@@ -138,16 +313,94 @@ func (dds V2DomainDefinitions) String() string {
 
 func (dds V2DomainDefinitions) validate(exportedPorts PortDefinitions) error {
 	for domainName, ports := range dds {
-		if err := domainName.Validate(); err != nil {
+		if err := validateDomainName(domainName); err != nil {
+			if d, ok := DiagnosticOf(err); ok {
+				d.withField(fmt.Sprintf("[%q]", domainName)).withValue(domainName)
+			}
 			return mask(err)
 		}
 
 		for _, port := range ports {
+			field := fmt.Sprintf("[%q].port", domainName)
+			if port.Protocol != generictypes.ProtocolTCP {
+				return newDiagnostic(InvalidDomainDefinitionError, "port '%s' of domain '%s' must use protocol '%s'", port, domainName, generictypes.ProtocolTCP).withField(field).withValue(port)
+			}
 			if !exportedPorts.contains(port) {
-				return maskf(InvalidDomainDefinitionError, "port '%s' of domain '%s' must be exported", port, domainName)
+				return newDiagnostic(InvalidDomainDefinitionError, "port '%s' of domain '%s' must be exported", port, domainName).withField(field).withValue(port).withFix("add '%s' to ports", port)
 			}
 		}
 	}
 
 	return nil
 }
+
+// validate checks the settings of the given domains.
+func (dss V2DomainSettings) validate(dds V2DomainDefinitions) error {
+	for domainName, ds := range dss {
+		if _, ok := dds[domainName]; !ok {
+			return newDiagnostic(InvalidDomainDefinitionError, "settings of domain '%s' require the domain to be bound", domainName).withField(fmt.Sprintf("[%q]", domainName)).withValue(domainName)
+		}
+		if err := ds.validate(domainName); err != nil {
+			return mask(diagnosticInField(err, fmt.Sprintf("[%q]", domainName)))
+		}
+	}
+
+	return nil
+}
+
+// validate checks the settings of the given domain.
+func (ds DomainSettings) validate(domainName generictypes.Domain) error {
+	if ds.TLS != nil {
+		if err := ds.TLS.validate(domainName); err != nil {
+			return mask(diagnosticInField(err, "tls"))
+		}
+	}
+
+	if ds.RedirectHTTPS && ds.TLS == nil {
+		return newDiagnostic(InvalidDomainDefinitionError, "redirect-https of domain '%s' requires tls", domainName).withField("redirect-https").withFix("add tls to domain '%s'", domainName)
+	}
+
+	if err := ds.validatePaths(domainName); err != nil {
+		return mask(diagnosticInField(err, "paths"))
+	}
+
+	return nil
+}
+
+// validateDomainName validates the given domain, which may be a wildcard
+// domain like "*.example.com".
+func validateDomainName(domain generictypes.Domain) error {
+	name := domain.String()
+	if isWildcardDomain(domain) {
+		name = strings.TrimPrefix(name, wildcardPrefix)
+	}
+	if strings.Contains(name, "*") {
+		return maskf(InvalidDomainDefinitionError, "invalid wildcard domain '%s', only '%s' prefix is supported", domain, wildcardPrefix)
+	}
+
+	if err := generictypes.Domain(name).Validate(); err != nil {
+		return mask(err)
+	}
+
+	return nil
+}
+
+// isWildcardDomain returns true if the given domain matches all direct
+// subdomains, e.g. "*.example.com".
+func isWildcardDomain(domain generictypes.Domain) bool {
+	return strings.HasPrefix(domain.String(), wildcardPrefix)
+}
+
+// wildcardDomainOf returns the wildcard domain that matches the given domain,
+// e.g. "*.example.com" for "www.example.com". Wildcard domains and top level
+// domains are not matched by any wildcard domain.
+func wildcardDomainOf(domain generictypes.Domain) (generictypes.Domain, bool) {
+	if isWildcardDomain(domain) {
+		return "", false
+	}
+	parts := strings.SplitN(domain.String(), ".", 2)
+	if len(parts) != 2 || !strings.Contains(strings.TrimSuffix(parts[1], "."), ".") {
+		return "", false
+	}
+	return generictypes.Domain(wildcardPrefix + parts[1]), true
+}
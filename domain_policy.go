@@ -0,0 +1,168 @@
+package userconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/generic-types-go"
+)
+
+// Rules of domain policies, as reported by DomainPolicyViolation.
+const (
+	DomainRuleSuffixNotAllowed = "suffix-not-allowed"
+	DomainRuleNotVerified      = "not-verified"
+)
+
+// DomainPolicy decides whether an organization may bind a domain.
+type DomainPolicy interface {
+	// CheckDomain returns nil if the given organization may bind the given
+	// domain. To reject the domain, a *DomainPolicyViolation is returned.
+	// Any other error is returned as is by the validation.
+	CheckDomain(org string, domain generictypes.Domain) error
+}
+
+// DomainPolicyViolation describes which rule of a domain policy rejected a
+// domain. It is returned as underlying error of an
+// InvalidDomainDefinitionError. Use DomainPolicyViolationOf to get it from an
+// error.
+type DomainPolicyViolation struct {
+	// Rule that fired, e.g. DomainRuleNotVerified.
+	Rule string
+
+	// Organization the domain was rejected for.
+	Org string
+
+	// Domain that was rejected.
+	Domain generictypes.Domain
+
+	Message string
+}
+
+func (v *DomainPolicyViolation) Error() string {
+	return v.Message
+}
+
+// NewDomainPolicyViolation returns a DomainPolicyViolation for the given
+// rule, for use by implementations of DomainPolicy.
+func NewDomainPolicyViolation(rule, org string, domain generictypes.Domain, f string, a ...interface{}) *DomainPolicyViolation {
+	return &DomainPolicyViolation{
+		Rule:    rule,
+		Org:     org,
+		Domain:  domain,
+		Message: fmt.Sprintf(f, a...),
+	}
+}
+
+// DomainPolicyViolationOf returns the domain policy violation the given
+// error was caused by, if any. Both errgo and fmt.Errorf wrapping are
+// followed.
+func DomainPolicyViolationOf(err error) (*DomainPolicyViolation, bool) {
+	for err != nil {
+		var v *DomainPolicyViolation
+		if errors.As(err, &v) {
+			return v, true
+		}
+		w, ok := err.(interface {
+			Underlying() error
+		})
+		if !ok {
+			return nil, false
+		}
+		err = w.Underlying()
+	}
+	return nil, false
+}
+
+// DomainSuffixPolicy allows domains matching one of its patterns, e.g.
+// "*.{org}.gigantic.io". A pattern starting with "*." matches all subdomains
+// of the rest of the pattern, other patterns match a single domain. The
+// "{org}" placeholder is replaced by the organization.
+type DomainSuffixPolicy []string
+
+func (p DomainSuffixPolicy) CheckDomain(org string, domain generictypes.Domain) error {
+	name := domainPolicyName(domain)
+	for _, pattern := range p {
+		pattern = strings.ToLower(strings.Replace(pattern, orgPlaceholder, org, -1))
+		if strings.HasPrefix(pattern, wildcardPrefix) {
+			if strings.HasSuffix(name, pattern[1:]) {
+				return nil
+			}
+		} else if name == pattern {
+			return nil
+		}
+	}
+
+	return NewDomainPolicyViolation(DomainRuleSuffixNotAllowed, org, domain, "domain '%s' does not match any allowed suffix for organization '%s'", domain, org)
+}
+
+// VerifiedDomainPolicy allows the domains that have been verified for an
+// organization, e.g. "acme" => ["acme.com"]. Subdomains of a verified domain
+// are allowed too.
+type VerifiedDomainPolicy map[string][]generictypes.Domain
+
+func (p VerifiedDomainPolicy) CheckDomain(org string, domain generictypes.Domain) error {
+	name := domainPolicyName(domain)
+	for _, verified := range p[org] {
+		v := normalizeDomainName(verified).String()
+		if name == v || strings.HasSuffix(name, "."+v) {
+			return nil
+		}
+	}
+
+	return NewDomainPolicyViolation(DomainRuleNotVerified, org, domain, "domain '%s' is not verified for organization '%s'", domain, org)
+}
+
+// AnyDomainPolicy allows a domain if any of its policies allows it. If all
+// reject the domain, the violation of the first policy is returned.
+type AnyDomainPolicy []DomainPolicy
+
+func (p AnyDomainPolicy) CheckDomain(org string, domain generictypes.Domain) error {
+	var first error
+	for _, policy := range p {
+		err := policy.CheckDomain(org, domain)
+		if err == nil {
+			return nil
+		}
+		if _, ok := DomainPolicyViolationOf(err); !ok {
+			return err
+		}
+		if first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// domainPolicyName returns the name of the given domain as checked by the
+// built-in policies. A wildcard domain is checked as one of the subdomains it
+// matches, so "*.acme.com" is allowed wherever "x.acme.com" is.
+func domainPolicyName(domain generictypes.Domain) string {
+	name := normalizeDomainName(domain).String()
+	if isWildcardDomain(domain) {
+		name = "x" + name[1:]
+	}
+	return name
+}
+
+// validateDomainPolicy checks all domains of this service against the given
+// policy.
+func (sd *ServiceDefinition) validateDomainPolicy(org string, policy DomainPolicy) error {
+	checked := map[generictypes.Domain]bool{}
+	for _, r := range sd.Components.routes() {
+		if checked[r.Domain] {
+			continue
+		}
+		checked[r.Domain] = true
+
+		err := policy.CheckDomain(org, r.Domain)
+		if v, ok := DomainPolicyViolationOf(err); ok {
+			return newDiagnostic(InvalidDomainDefinitionError, "").withComponent(r.Component).withField(fmt.Sprintf("domains[%q]", r.Domain)).withValue(r.Domain).withUnderlying(v)
+		} else if err != nil {
+			return mask(err)
+		}
+	}
+
+	return nil
+}
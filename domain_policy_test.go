@@ -0,0 +1,136 @@
+package userconfig_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/giantswarm/generic-types-go"
+	"github.com/giantswarm/user-config"
+)
+
+func TestDomainPolicies(t *testing.T) {
+	suffix := userconfig.DomainSuffixPolicy{"*.{org}.gigantic.io", "{org}.example.com"}
+	verified := userconfig.VerifiedDomainPolicy{"acme": {"acme.com", "Acme.io"}}
+	list := []struct {
+		Policy userconfig.DomainPolicy
+		Org    string
+		Domain generictypes.Domain
+		Rule   string // Empty if allowed
+	}{
+		{suffix, "acme", "www.acme.gigantic.io", ""},
+		{suffix, "acme", "a.b.acme.gigantic.io", ""},
+		{suffix, "acme", "*.acme.gigantic.io", ""},
+		{suffix, "acme", "acme.example.com", ""},
+		{suffix, "acme", "acme.gigantic.io", userconfig.DomainRuleSuffixNotAllowed},
+		{suffix, "acme", "www.other.gigantic.io", userconfig.DomainRuleSuffixNotAllowed},
+		{suffix, "acme", "*.gigantic.io", userconfig.DomainRuleSuffixNotAllowed},
+		{suffix, "acme", "www.acme.example.com", userconfig.DomainRuleSuffixNotAllowed},
+		{verified, "acme", "acme.com", ""},
+		{verified, "acme", "www.ACME.com", ""},
+		{verified, "acme", "shop.acme.io", ""},
+		{verified, "acme", "*.acme.com", ""},
+		{verified, "acme", "notacme.com", userconfig.DomainRuleNotVerified},
+		{verified, "other", "acme.com", userconfig.DomainRuleNotVerified},
+		{userconfig.AnyDomainPolicy{suffix, verified}, "acme", "acme.com", ""},
+		{userconfig.AnyDomainPolicy{suffix, verified}, "acme", "www.acme.gigantic.io", ""},
+		{userconfig.AnyDomainPolicy{suffix, verified}, "acme", "evil.com", userconfig.DomainRuleSuffixNotAllowed},
+	}
+
+	for i, test := range list {
+		err := test.Policy.CheckDomain(test.Org, test.Domain)
+		if test.Rule == "" {
+			if err != nil {
+				t.Fatalf("Test %d: domain '%s' rejected: %v", i, test.Domain, err)
+			}
+			continue
+		}
+		v, ok := err.(*userconfig.DomainPolicyViolation)
+		if !ok {
+			t.Fatalf("Test %d: expected violation for domain '%s', got %v", i, test.Domain, err)
+		}
+		if v.Rule != test.Rule {
+			t.Fatalf("Test %d: expected rule '%s', got '%s'", i, test.Rule, v.Rule)
+		}
+	}
+}
+
+func TestDomainPolicyValidation(t *testing.T) {
+	port := userconfig.PortDefinitions{generictypes.MustParseDockerPort("80/tcp")}
+	list := []struct {
+		Domain generictypes.Domain
+		Valid  bool
+	}{
+		{"www.acme.gigantic.io", true},
+		{"www.other.gigantic.io", false},
+	}
+
+	for i, test := range list {
+		def := ExampleDefinition()
+		def.Components["component/a"].Domains = userconfig.V2DomainDefinitions{test.Domain: port}
+
+		valCtx := NewValidationContext()
+		valCtx.Org = "acme"
+		valCtx.DomainPolicy = userconfig.DomainSuffixPolicy{"*.{org}.gigantic.io"}
+		if err := def.SetDefaults(valCtx); err != nil {
+			t.Fatalf("Test %d: SetDefaults failed: %v", i, err)
+		}
+
+		err := def.Validate(valCtx)
+		if test.Valid {
+			if err != nil {
+				t.Fatalf("Test %d: valid domain considered invalid because %v", i, err)
+			}
+			continue
+		}
+		if !userconfig.IsInvalidDomainDefinition(err) {
+			t.Fatalf("Test %d: expected InvalidDomainDefinitionError, got %v", i, err)
+		}
+		v, ok := userconfig.DomainPolicyViolationOf(err)
+		if !ok {
+			t.Fatalf("Test %d: expected domain policy violation, got %v", i, err)
+		}
+		if v.Rule != userconfig.DomainRuleSuffixNotAllowed || v.Org != "acme" {
+			t.Fatalf("Test %d: unexpected violation %#v", i, v)
+		}
+	}
+}
+
+// wrappingDomainPolicy returns the violations of its policy wrapped, like a
+// policy that adds context to the errors of another one.
+type wrappingDomainPolicy struct {
+	userconfig.DomainPolicy
+}
+
+func (p wrappingDomainPolicy) CheckDomain(org string, domain generictypes.Domain) error {
+	if err := p.DomainPolicy.CheckDomain(org, domain); err != nil {
+		return fmt.Errorf("checking domain '%s': %w", domain, err)
+	}
+	return nil
+}
+
+func TestWrappedDomainPolicyViolation(t *testing.T) {
+	suffix := wrappingDomainPolicy{userconfig.DomainSuffixPolicy{"*.{org}.gigantic.io"}}
+	verified := userconfig.VerifiedDomainPolicy{"acme": {"acme.com"}}
+
+	// A wrapped violation does not stop AnyDomainPolicy from trying the others
+	if err := (userconfig.AnyDomainPolicy{suffix, verified}).CheckDomain("acme", "acme.com"); err != nil {
+		t.Fatalf("domain 'acme.com' rejected: %v", err)
+	}
+
+	def := ExampleDefinition()
+	def.Components["component/a"].Domains = userconfig.V2DomainDefinitions{"www.other.gigantic.io": {generictypes.MustParseDockerPort("80/tcp")}}
+	valCtx := NewValidationContext()
+	valCtx.Org = "acme"
+	valCtx.DomainPolicy = suffix
+	if err := def.SetDefaults(valCtx); err != nil {
+		t.Fatalf("SetDefaults failed: %v", err)
+	}
+
+	err := def.Validate(valCtx)
+	if !userconfig.IsInvalidDomainDefinition(err) {
+		t.Fatalf("expected InvalidDomainDefinitionError, got %v", err)
+	}
+	if v, ok := userconfig.DomainPolicyViolationOf(err); !ok || v.Rule != userconfig.DomainRuleSuffixNotAllowed {
+		t.Fatalf("expected suffix-not-allowed violation, got %v", err)
+	}
+}
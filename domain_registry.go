@@ -0,0 +1,81 @@
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/generic-types-go"
+)
+
+// DomainRegistry provides the domains that are bound by other services, e.g.
+// by asking the router.
+type DomainRegistry interface {
+	// DomainOwner returns the name of the service the given domain is bound
+	// to, or an empty string if the domain is not bound. A domain is also
+	// bound, if a wildcard domain matching it is bound, e.g. "*.example.com"
+	// for "www.example.com".
+	DomainOwner(domain generictypes.Domain) (string, error)
+}
+
+// InMemoryDomainRegistry is a DomainRegistry that looks up domains in a map
+// of domains to service names, e.g. "www.example.com" => "website". It is
+// meant to be used in tests.
+type InMemoryDomainRegistry map[generictypes.Domain]string
+
+func (r InMemoryDomainRegistry) DomainOwner(domain generictypes.Domain) (string, error) {
+	if serviceName, ok := r.lookup(domain); ok {
+		return serviceName, nil
+	}
+	// The domain itself is not bound, but maybe by a wildcard domain
+	if wildcard, ok := wildcardDomainOf(domain); ok {
+		if serviceName, ok := r.lookup(wildcard); ok {
+			return serviceName, nil
+		}
+	}
+
+	return "", nil
+}
+
+// lookup returns the service the given domain is bound to, if any.
+func (r InMemoryDomainRegistry) lookup(domain generictypes.Domain) (string, bool) {
+	for d, serviceName := range r {
+		if normalizeDomainName(d) == normalizeDomainName(domain) {
+			return serviceName, true
+		}
+	}
+	return "", false
+}
+
+// normalizeDomainName returns the given domain in the form used to compare
+// domains. Domain names are case insensitive and may be written fully
+// qualified with a trailing dot.
+func normalizeDomainName(domain generictypes.Domain) generictypes.Domain {
+	return generictypes.Domain(strings.TrimSuffix(strings.ToLower(domain.String()), "."))
+}
+
+// validateDomainRegistry checks that none of the domains of this service is
+// bound by another service in the given registry.
+func (sd *ServiceDefinition) validateDomainRegistry(registry DomainRegistry) error {
+	serviceName, err := sd.Name()
+	if err != nil {
+		return mask(err)
+	}
+
+	checked := map[generictypes.Domain]bool{}
+	for _, r := range sd.Components.routes() {
+		if checked[r.Domain] {
+			continue
+		}
+		checked[r.Domain] = true
+
+		owner, err := registry.DomainOwner(r.Domain)
+		if err != nil {
+			return mask(err)
+		}
+		if owner != "" && owner != serviceName {
+			return newDiagnostic(DuplicateDomainError, "domain '%s' of component '%s' is already bound by service '%s'", r.Domain, r.Component, owner).withComponent(r.Component).withField(fmt.Sprintf("domains[%q]", r.Domain)).withValue(r.Domain)
+		}
+	}
+
+	return nil
+}
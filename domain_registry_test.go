@@ -0,0 +1,108 @@
+package userconfig_test
+
+import (
+	"testing"
+
+	"github.com/giantswarm/generic-types-go"
+	"github.com/giantswarm/user-config"
+)
+
+func TestUniqueDomains(t *testing.T) {
+	port := userconfig.PortDefinitions{generictypes.MustParseDockerPort("80/tcp")}
+	list := []struct {
+		DomainA generictypes.Domain
+		DomainB generictypes.Domain
+		Valid   bool
+	}{
+		{"a.example.com", "b.example.com", true},
+		{"*.example.com", "www.example.com", true},
+		{"www.example.com", "www.example.com", false},
+		{"WWW.example.com", "www.Example.com", false},
+	}
+
+	for i, test := range list {
+		def := ExampleDefinition()
+		def.Components["component/a"].Domains = userconfig.V2DomainDefinitions{test.DomainA: port}
+		def.Components["component/b"].Domains = userconfig.V2DomainDefinitions{test.DomainB: port}
+
+		err := def.Validate(nil)
+		if test.Valid && err != nil {
+			t.Fatalf("Test %d: valid domains considered invalid because %v", i, err)
+		}
+		if !test.Valid {
+			if err == nil {
+				t.Fatalf("Test %d: duplicate domains considered valid", i)
+			}
+			if !userconfig.IsDuplicateDomain(err) || !userconfig.IsInvalidDomainDefinition(err) {
+				t.Fatalf("Test %d: expected DuplicateDomainError, got %v", i, err)
+			}
+		}
+	}
+}
+
+func TestUniqueDomainsPerPort(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/b"].Ports = userconfig.PortDefinitions{generictypes.MustParseDockerPort("80/tcp"), generictypes.MustParseDockerPort("8080/tcp")}
+	def.Components["component/a"].Domains = userconfig.V2DomainDefinitions{"www.example.com": {generictypes.MustParseDockerPort("80/tcp")}}
+	def.Components["component/b"].Domains = userconfig.V2DomainDefinitions{"www.example.com": {generictypes.MustParseDockerPort("8080/tcp")}}
+	if err := def.Validate(nil); err != nil {
+		t.Fatalf("domain bound to different ports considered invalid because %v", err)
+	}
+
+	def.Components["component/b"].Domains = userconfig.V2DomainDefinitions{"www.example.com": {generictypes.MustParseDockerPort("80/tcp")}}
+	if err := def.Validate(nil); !userconfig.IsDuplicateDomain(err) {
+		t.Fatalf("expected DuplicateDomainError, got %v", err)
+	}
+}
+
+func TestDomainRegistry(t *testing.T) {
+	registry := userconfig.InMemoryDomainRegistry{
+		"www.example.com":    "website",
+		"Shop.Example.com":   "shop",
+		"*.apps.example.com": "apps",
+	}
+	port := userconfig.PortDefinitions{generictypes.MustParseDockerPort("80/tcp")}
+
+	list := []struct {
+		ServiceName userconfig.ServiceName
+		Domain      generictypes.Domain
+		Valid       bool
+	}{
+		{"website", "new.example.com", true},
+		{"website", "www.example.com", true},
+		{"other", "www.example.com", false},
+		{"other", "shop.example.com", false},
+		{"shop", "shop.example.com", true},
+		// Bound through a wildcard domain
+		{"other", "a.apps.example.com", false},
+		{"apps", "a.apps.example.com", true},
+		{"other", "a.b.apps.example.com", true},
+		{"other", "*.apps.example.com", false},
+		{"other", "apps.example.com", true},
+	}
+
+	for i, test := range list {
+		def := ExampleDefinition()
+		def.ServiceName = test.ServiceName
+		def.Components["component/a"].Domains = userconfig.V2DomainDefinitions{test.Domain: port}
+
+		valCtx := NewValidationContext()
+		valCtx.DomainRegistry = registry
+		if err := def.SetDefaults(valCtx); err != nil {
+			t.Fatalf("Test %d: SetDefaults failed: %v", i, err)
+		}
+
+		err := def.Validate(valCtx)
+		if test.Valid && err != nil {
+			t.Fatalf("Test %d: valid domain considered invalid because %v", i, err)
+		}
+		if !test.Valid {
+			if err == nil {
+				t.Fatalf("Test %d: domain of other service considered valid", i)
+			}
+			if !userconfig.IsDuplicateDomain(err) {
+				t.Fatalf("Test %d: expected DuplicateDomainError, got %v", i, err)
+			}
+		}
+	}
+}
@@ -157,3 +157,152 @@ func TestDomainStringReliability(t *testing.T) {
 		}
 	}
 }
+
+func TestV2DomainSettings(t *testing.T) {
+	var nd userconfig.ComponentDefinition
+	input := `{
+		"image": "busybox",
+		"ports": [ "80/tcp" ],
+		"domains": {
+			"80": [ "plain.com" ],
+			"secure.com": { "port": "80", "tls": { "certificate": "secure-cert" }, "redirect-https": true },
+			"auto.com": { "port": 80, "tls": { "auto": true } }
+		}
+	}`
+	if err := json.Unmarshal([]byte(input), &nd); err != nil {
+		t.Fatalf("Valid domain definitions considered invalid because %v", err)
+	}
+	if len(nd.Domains) != 3 {
+		t.Fatalf("Invalid length, expected 3, got %v", len(nd.Domains))
+	}
+	if len(nd.DomainSettings) != 2 {
+		t.Fatalf("Invalid length of settings, expected 2, got %v", len(nd.DomainSettings))
+	}
+
+	secure := nd.DomainSettings["secure.com"]
+	if secure.TLS == nil || secure.TLS.Certificate != "secure-cert" || !secure.RedirectHTTPS {
+		t.Fatalf("Invalid settings for secure.com: %#v", secure)
+	}
+	if ports := nd.Domains["secure.com"]; len(ports) != 1 || ports[0].String() != "80/tcp" {
+		t.Fatalf("Invalid ports for secure.com: %v", ports)
+	}
+	auto := nd.DomainSettings["auto.com"]
+	if auto.TLS == nil || !auto.TLS.Auto || auto.RedirectHTTPS {
+		t.Fatalf("Invalid settings for auto.com: %#v", auto)
+	}
+	if _, ok := nd.DomainSettings["plain.com"]; ok {
+		t.Fatalf("Expected no settings for plain.com")
+	}
+
+	// Marshal and unmarshal again, settings must survive
+	data, err := json.Marshal(nd)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var again userconfig.ComponentDefinition
+	if err := json.Unmarshal(data, &again); err != nil {
+		t.Fatalf("Unmarshal of '%s' failed: %v", string(data), err)
+	}
+	if nd.Domains.String() != again.Domains.String() {
+		t.Fatalf("Expected '%s', got '%s'", nd.Domains.String(), again.Domains.String())
+	}
+	if nd.DomainSettings.String() != again.DomainSettings.String() {
+		t.Fatalf("Expected '%s', got '%s'", nd.DomainSettings.String(), again.DomainSettings.String())
+	}
+
+	// Without a component, the settings are dropped
+	var dds userconfig.V2DomainDefinitions
+	if err := json.Unmarshal([]byte(`{ "secure.com": { "port": "80", "tls": { "auto": true } } }`), &dds); err != nil {
+		t.Fatalf("Valid domain definitions considered invalid because %v", err)
+	}
+	if ports := dds["secure.com"]; len(ports) != 1 || ports[0].String() != "80/tcp" {
+		t.Fatalf("Invalid ports for secure.com: %v", ports)
+	}
+}
+
+func TestV2DomainSettingsValidation(t *testing.T) {
+	port := userconfig.PortDefinitions{generictypes.MustParseDockerPort("80/tcp")}
+	list := []struct {
+		Domain   generictypes.Domain
+		Settings *userconfig.DomainSettings
+		Valid    bool
+	}{
+		{"foo.com", nil, true},
+		{"*.foo.com", nil, true},
+		{"foo.*.com", nil, false},
+		{"*foo.com", nil, false},
+		{"*.*.foo.com", nil, false},
+		{"foo.com", &userconfig.DomainSettings{TLS: &userconfig.TLSDefinition{Auto: true}}, true},
+		{"foo.com", &userconfig.DomainSettings{TLS: &userconfig.TLSDefinition{Certificate: "cert"}, RedirectHTTPS: true}, true},
+		{"foo.com", &userconfig.DomainSettings{TLS: &userconfig.TLSDefinition{}}, false},
+		{"foo.com", &userconfig.DomainSettings{TLS: &userconfig.TLSDefinition{Auto: true, Certificate: "cert"}}, false},
+		{"foo.com", &userconfig.DomainSettings{RedirectHTTPS: true}, false},
+		{"*.foo.com", &userconfig.DomainSettings{TLS: &userconfig.TLSDefinition{Certificate: "cert"}}, true},
+		{"*.foo.com", &userconfig.DomainSettings{TLS: &userconfig.TLSDefinition{Auto: true}}, false},
+	}
+
+	for i, test := range list {
+		def := ExampleDefinition()
+		c := def.Components["component/a"]
+		c.Domains = userconfig.V2DomainDefinitions{test.Domain: port}
+		if test.Settings != nil {
+			c.DomainSettings = userconfig.V2DomainSettings{test.Domain: *test.Settings}
+		}
+		def.Components["component/a"] = c
+
+		err := def.Validate(nil)
+		if test.Valid && err != nil {
+			t.Fatalf("Test %d: Valid domain '%s' considered invalid because %v", i, test.Domain, err)
+		}
+		if !test.Valid {
+			if err == nil {
+				t.Fatalf("Test %d: Invalid domain '%s' considered valid", i, test.Domain)
+			}
+			if !userconfig.IsInvalidDomainDefinition(err) {
+				t.Fatalf("Test %d: Expected InvalidDomainDefinitionError, got %v", i, err)
+			}
+		}
+	}
+}
+
+func TestV2DomainSettingsWithoutDomain(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/a"].DomainSettings = userconfig.V2DomainSettings{
+		"foo.com": {TLS: &userconfig.TLSDefinition{Auto: true}},
+	}
+
+	if err := def.Validate(nil); !userconfig.IsInvalidDomainDefinition(err) {
+		t.Fatalf("Expected InvalidDomainDefinitionError, got %v", err)
+	}
+}
+
+func TestV2DomainSettingsFullService(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"component/a": {
+				"image": "busybox",
+				"ports": [ "80/tcp", "81/tcp" ],
+				"domains": {
+					"80": [ "plain.com" ],
+					"short.com": { "port": "81" },
+					"*.secure.com": { "port": [ "81", 80 ], "tls": { "certificate": "wildcard-cert" }, "redirect-https": true }
+				}
+			}
+		}
+	}`)
+
+	def, err := userconfig.ParseServiceDefinition(b)
+	if err != nil {
+		t.Fatalf("ParseServiceDefinition failed: %v", err)
+	}
+	domains := def.Components["component/a"].Domains
+	if len(domains) != 3 {
+		t.Fatalf("Invalid length, expected 3, got %v", len(domains))
+	}
+	if len(domains["*.secure.com"]) != 2 {
+		t.Fatalf("Expected 2 ports for *.secure.com, got %v", domains["*.secure.com"])
+	}
+	if settings := def.Components["component/a"].DomainSettings; len(settings) != 1 || settings["*.secure.com"].TLS == nil {
+		t.Fatalf("Expected settings for *.secure.com only, got %v", settings)
+	}
+}
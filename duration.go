@@ -0,0 +1,77 @@
+package userconfig
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+var (
+	InvalidDurationFormatError = errgo.Newf("Invalid duration format")
+)
+
+func IsInvalidDurationFormat(err error) bool {
+	return errgo.Cause(err) == InvalidDurationFormatError
+}
+
+// Duration describes a period of time like "10s", "1m30s" or "500ms". A plain
+// number without unit is interpreted as seconds.
+type Duration string
+
+// IsEmpty returns true if the underlying string is empty
+func (d Duration) IsEmpty() bool {
+	return d.String() == ""
+}
+
+// Valid returns a bool indicating whether this Duration value can successfully be parsed.
+func (d Duration) Valid() bool {
+	if d.IsEmpty() {
+		return false
+	}
+	_, err := d.Duration()
+	return err == nil
+}
+
+// Duration parses the period of time described by this Duration.
+// If the value of d is unparsable, an error is returned.
+// Example: if the value contains "1m30s", 90 * time.Second will be returned.
+func (d Duration) Duration() (time.Duration, error) {
+	s := strings.TrimSpace(string(d))
+
+	// A plain number is given in seconds
+	if seconds, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	value, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errgo.WithCausef(nil, InvalidDurationFormatError, "Input: %s", string(d))
+	}
+	if value < 0 {
+		return 0, errgo.WithCausef(nil, InvalidDurationFormatError, "Input: %s", string(d))
+	}
+
+	return value, nil
+}
+
+// Equals returns true if both durations describe the same period of time.
+func (d Duration) Equals(other Duration) bool {
+	myValue, err := d.Duration()
+	if err != nil {
+		return false
+	}
+
+	otherValue, err := other.Duration()
+	if err != nil {
+		return false
+	}
+
+	return myValue == otherValue
+}
+
+// String returns the string value of this Duration as initially provided.
+func (d Duration) String() string {
+	return string(d)
+}
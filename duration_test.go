@@ -0,0 +1,45 @@
+package userconfig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationParse(t *testing.T) {
+	tests := []struct {
+		input string
+		valid bool
+		value time.Duration
+	}{
+		{"10s", true, 10 * time.Second},
+		{" 10s ", true, 10 * time.Second},
+		{"1m30s", true, 90 * time.Second},
+		{"500ms", true, 500 * time.Millisecond},
+		{"30", true, 30 * time.Second},
+		{"0", true, 0},
+		{"", false, 0},
+		{"-5s", false, 0},
+		{"10 seconds", false, 0},
+		{"s", false, 0},
+	}
+
+	for idx, test := range tests {
+		d := Duration(test.input)
+
+		v, err := d.Duration()
+		if test.valid {
+			if err != nil {
+				t.Errorf("Test %d, Duration(%s) returned unexpected error: %v", idx, test.input, err)
+			}
+		} else {
+			if err == nil {
+				t.Errorf("Test %d, Duration(%s) expected error, but received nil.", idx, test.input)
+			} else if !IsInvalidDurationFormat(err) {
+				t.Errorf("Test %d, Duration(%s) expected InvalidDurationFormatError, got %v", idx, test.input, err)
+			}
+		}
+		if v != test.value {
+			t.Errorf("Test %d, Duration(%s): Expected %v, got %v\n", idx, test.input, test.value, v)
+		}
+	}
+}
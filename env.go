@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/juju/errgo"
 )
@@ -69,3 +70,15 @@ func (eds *EnvList) String() string {
 
 	return string(raw)
 }
+
+// Keys returns the names of all environment variables in this list, in the
+// order they are given.
+func (eds EnvList) Keys() []string {
+	keys := []string{}
+
+	for _, ed := range eds {
+		keys = append(keys, strings.SplitN(ed, "=", 2)[0])
+	}
+
+	return keys
+}
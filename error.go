@@ -6,36 +6,50 @@ import (
 )
 
 var (
-	UnknownJSONFieldError           = errgo.New("unknown JSON field")
-	MissingJSONFieldError           = errgo.New("missing JSON field")
-	InvalidSizeError                = errgo.New("invalid size")
-	DuplicateVolumePathError        = errgo.New("duplicate volume path")
-	InvalidEnvListFormatError       = errgo.Newf("unable to parse 'env', objects or Array of strings expected")
-	CrossServicePodError            = errgo.New("pod is used in different services")
-	PodUsedOnlyOnceError            = errgo.New("pod is used in only 1 component")
-	InvalidVolumeConfigError        = errgo.New("invalid volume configuration")
-	InvalidDependencyConfigError    = errgo.New("invalid dependency configuration")
-	InvalidScalingConfigError       = errgo.New("invalid scaling configuration")
-	InvalidPortConfigError          = errgo.New("Invalid port configuration")
-	InvalidDomainDefinitionError    = errgo.New("invalid domain definition")
-	InvalidLinkDefinitionError      = errgo.New("invalid link definition")
-	InvalidAppDefinitionError       = errgo.New("invalid service definition")
-	InvalidComponentDefinitionError = errgo.New("invalid component definition")
-	InvalidImageDefinitionError     = errgo.New("invalid image definition")
-	InvalidServiceNameError         = errgo.New("invalid service name")
-	InvalidComponentNameError       = errgo.New("invalid component name")
-	InvalidPodConfigError           = errgo.New("invalid pod configuration")
-	PortNotFoundError               = errgo.New("port not found")
-	ComponentNotFoundError          = errgo.New("component not found")
-	InternalError                   = errgo.New("internal error")
-	MissingValidationContextError   = errgo.New("missing validation context")
-	InvalidArgumentError            = errgo.New("invalid argument")
-	VolumeCycleError                = errgo.New("cycle detected in volume configuration")
-	WrongDiffOrderError             = errgo.New("wrong diff order")
-	LinkCycleError                  = errgo.New("cycle detected in link definition")
-	InvalidMemoryLimitError         = errgo.New("Invalid 'memory-limit' field")
-
-	mask = errgo.MaskFunc(IsInvalidEnvListFormat,
+	UnknownJSONFieldError             = errgo.New("unknown JSON field")
+	MissingJSONFieldError             = errgo.New("missing JSON field")
+	InvalidSizeError                  = errgo.New("invalid size")
+	DuplicateVolumePathError          = errgo.New("duplicate volume path")
+	InvalidEnvListFormatError         = errgo.Newf("unable to parse 'env', objects or Array of strings expected")
+	CrossServicePodError              = errgo.New("pod is used in different services")
+	PodUsedOnlyOnceError              = errgo.New("pod is used in only 1 component")
+	InvalidVolumeConfigError          = errgo.New("invalid volume configuration")
+	InvalidDependencyConfigError      = errgo.New("invalid dependency configuration")
+	InvalidScalingConfigError         = errgo.New("invalid scaling configuration")
+	InvalidPortConfigError            = errgo.New("Invalid port configuration")
+	InvalidDomainDefinitionError      = errgo.New("invalid domain definition")
+	DuplicateDomainError              = errgo.New("duplicate domain")
+	InvalidLinkDefinitionError        = errgo.New("invalid link definition")
+	InvalidAppDefinitionError         = errgo.New("invalid service definition")
+	InvalidComponentDefinitionError   = errgo.New("invalid component definition")
+	InvalidImageDefinitionError       = errgo.New("invalid image definition")
+	InvalidServiceNameError           = errgo.New("invalid service name")
+	InvalidComponentNameError         = errgo.New("invalid component name")
+	InvalidPodConfigError             = errgo.New("invalid pod configuration")
+	PortNotFoundError                 = errgo.New("port not found")
+	ComponentNotFoundError            = errgo.New("component not found")
+	InternalError                     = errgo.New("internal error")
+	MissingValidationContextError     = errgo.New("missing validation context")
+	InvalidArgumentError              = errgo.New("invalid argument")
+	VolumeCycleError                  = errgo.New("cycle detected in volume configuration")
+	WrongDiffOrderError               = errgo.New("wrong diff order")
+	LinkCycleError                    = errgo.New("cycle detected in link definition")
+	InvalidMemoryLimitError           = errgo.New("Invalid 'memory-limit' field")
+	InvalidMemoryRequestError         = errgo.New("Invalid 'memory-request' field")
+	InvalidMemorySwapError            = errgo.New("Invalid 'memory-swap' field")
+	InvalidHealthCheckDefinitionError = errgo.New("invalid health check definition")
+	InvalidSecretDefinitionError      = errgo.New("invalid secret definition")
+	InvalidFileDefinitionError        = errgo.New("invalid file definition")
+	InvalidRestartPolicyError         = errgo.New("invalid restart policy")
+	InvalidStopSignalError            = errgo.New("Invalid 'stop-signal' field")
+	InvalidStopTimeoutError           = errgo.New("Invalid 'stop-timeout' field")
+	InvalidJobDefinitionError         = errgo.New("invalid job definition")
+	InvalidUpdateStrategyError        = errgo.New("invalid update strategy")
+	ImageDigestNotFoundError          = errgo.New("image digest not found")
+	InvalidCPULimitError              = errgo.New("Invalid 'cpu-limit' field")
+	InvalidCPURequestError            = errgo.New("Invalid 'cpu-request' field")
+
+	mask = keepDiagnostic(errgo.MaskFunc(IsInvalidEnvListFormat,
 		IsUnknownJsonField,
 		IsMissingJsonField,
 		IsInvalidSize,
@@ -49,6 +63,7 @@ var (
 		IsInvalidPortConfig,
 		IsInvalidPodConfig,
 		IsInvalidDomainDefinition,
+		IsDuplicateDomain,
 		IsInvalidLinkDefinition,
 		IsInvalidAppDefinition,
 		IsInvalidComponentDefinition,
@@ -63,26 +78,86 @@ var (
 		IsSyntax,
 		IsLinkCycle,
 		IsInvalidMemoryLimitError,
-	)
-
-	maskAny = errgo.MaskFunc(errgo.Any)
+		IsInvalidMemoryRequest,
+		IsInvalidMemorySwap,
+		IsInvalidHealthCheckDefinition,
+		IsInvalidSecretDefinition,
+		IsInvalidFileDefinition,
+		IsInvalidRestartPolicy,
+		IsInvalidStopSignal,
+		IsInvalidStopTimeout,
+		IsInvalidJobDefinition,
+		IsInvalidUpdateStrategy,
+		IsImageDigestNotFound,
+		IsInvalidCPULimit,
+		IsInvalidCPURequest,
+	))
+
+	maskAny = keepDiagnostic(errgo.MaskFunc(errgo.Any))
 )
 
-// maskf is short for mask(errgo.WithCausef(nil, cause, f, a...))
+// maskf returns a Diagnostic with the given cause and message. Use
+// newDiagnostic to provide details like the offending value.
 func maskf(cause error, f string, a ...interface{}) error {
-	err := mask(errgo.WithCausef(nil, cause, f, a...))
-	// the above call with set this location instead of that of our caller.
-	// that is fixed below.
-	if e, _ := err.(*errgo.Err); e != nil {
-		e.SetLocation(1)
-	}
-	return err
+	return newDiagnostic(cause, f, a...)
 }
 
 func IsInvalidMemoryLimitError(err error) bool {
 	return errgo.Cause(err) == InvalidMemoryLimitError
 }
 
+func IsInvalidMemoryRequest(err error) bool {
+	return errgo.Cause(err) == InvalidMemoryRequestError
+}
+
+func IsInvalidMemorySwap(err error) bool {
+	return errgo.Cause(err) == InvalidMemorySwapError
+}
+
+func IsInvalidHealthCheckDefinition(err error) bool {
+	return errgo.Cause(err) == InvalidHealthCheckDefinitionError
+}
+
+func IsInvalidSecretDefinition(err error) bool {
+	return errgo.Cause(err) == InvalidSecretDefinitionError
+}
+
+func IsInvalidFileDefinition(err error) bool {
+	return errgo.Cause(err) == InvalidFileDefinitionError
+}
+
+func IsInvalidRestartPolicy(err error) bool {
+	return errgo.Cause(err) == InvalidRestartPolicyError
+}
+
+func IsInvalidStopSignal(err error) bool {
+	return errgo.Cause(err) == InvalidStopSignalError
+}
+
+func IsInvalidStopTimeout(err error) bool {
+	return errgo.Cause(err) == InvalidStopTimeoutError
+}
+
+func IsInvalidJobDefinition(err error) bool {
+	return errgo.Cause(err) == InvalidJobDefinitionError
+}
+
+func IsInvalidUpdateStrategy(err error) bool {
+	return errgo.Cause(err) == InvalidUpdateStrategyError
+}
+
+func IsImageDigestNotFound(err error) bool {
+	return errgo.Cause(err) == ImageDigestNotFoundError
+}
+
+func IsInvalidCPULimit(err error) bool {
+	return errgo.Cause(err) == InvalidCPULimitError
+}
+
+func IsInvalidCPURequest(err error) bool {
+	return errgo.Cause(err) == InvalidCPURequestError
+}
+
 func IsUnknownJsonField(err error) bool {
 	return errgo.Cause(err) == UnknownJSONFieldError
 }
@@ -138,8 +213,15 @@ func IsInvalidPortConfig(err error) bool {
 	return errgo.Cause(err) == InvalidPortConfigError
 }
 
+// IsInvalidDomainDefinition returns true if the given error is of type
+// InvalidDomainDefinitionError or DuplicateDomainError. False otherwise.
 func IsInvalidDomainDefinition(err error) bool {
-	return errgo.Cause(err) == InvalidDomainDefinitionError
+	cause := errgo.Cause(err)
+	return cause == InvalidDomainDefinitionError || cause == DuplicateDomainError
+}
+
+func IsDuplicateDomain(err error) bool {
+	return errgo.Cause(err) == DuplicateDomainError
 }
 
 func IsInvalidLinkDefinition(err error) bool {
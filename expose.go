@@ -12,6 +12,60 @@ type ExposeDefinition struct {
 	Port       generictypes.DockerPort `json:"port" description:"Port of the stable API."`
 	Component  ComponentName           `json:"component,omitempty" description:"Name of the component that implements the stable API."`
 	TargetPort generictypes.DockerPort `json:"target_port,omitempty" description:"Port of the given component that implements the stable API."`
+
+	// Names of the ports of the implementation component, if they are referred
+	// to by name. Given as port and target_port, e.g. "http".
+	PortName       PortName `json:"-"`
+	TargetPortName PortName `json:"-"`
+}
+
+type exposeDefinitionCopy ExposeDefinition
+
+// UnmarshalJSON performs custom unmarshalling to support referring to ports
+// by name.
+func (ed *ExposeDefinition) UnmarshalJSON(data []byte) error {
+	var local struct {
+		exposeDefinitionCopy
+		Port       json.RawMessage `json:"port"`
+		TargetPort json.RawMessage `json:"target_port,omitempty"`
+	}
+	if err := decodeStrict(data, &local); err != nil {
+		return mask(err)
+	}
+
+	*ed = ExposeDefinition(local.exposeDefinitionCopy)
+	var err error
+	if len(local.Port) > 0 && string(local.Port) != "null" {
+		if ed.Port, ed.PortName, err = parsePortOrName(local.Port); err != nil {
+			return mask(err)
+		}
+	}
+	if len(local.TargetPort) > 0 && string(local.TargetPort) != "null" {
+		if ed.TargetPort, ed.TargetPortName, err = parsePortOrName(local.TargetPort); err != nil {
+			return mask(err)
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON performs custom marshalling to keep port names, if any.
+func (ed ExposeDefinition) MarshalJSON() ([]byte, error) {
+	local := struct {
+		exposeDefinitionCopy
+		Port       interface{} `json:"port"`
+		TargetPort interface{} `json:"target_port,omitempty"`
+	}{
+		exposeDefinitionCopy: exposeDefinitionCopy(ed),
+		Port:                 portOrName(ed.Port, ed.PortName),
+		TargetPort:           portOrName(ed.TargetPort, ed.TargetPortName),
+	}
+
+	data, err := json.Marshal(local)
+	if err != nil {
+		return nil, mask(err)
+	}
+	return data, nil
 }
 
 // String returns the string represantion of the current incarnation.
@@ -24,6 +78,12 @@ func (ed *ExposeDefinition) String() string {
 		"component":   ed.Component.String(),
 		"target_port": ed.TargetPort.String(),
 	}
+	if !ed.PortName.Empty() {
+		m["port_name"] = ed.PortName.String()
+	}
+	if !ed.TargetPortName.Empty() {
+		m["target_port_name"] = ed.TargetPortName.String()
+	}
 
 	raw, err := json.Marshal(m)
 	if err != nil {
@@ -40,30 +100,9 @@ func (nds ComponentDefinitions) validateExpose() error {
 	rootComponents := []*ComponentDefinition{}
 	for componentName, component := range nds {
 		// detect invalid exposes
-		for _, expose := range component.Expose {
-			// Try to find the implementation component
-			implName := expose.Component
-			var implComponent *ComponentDefinition
-			if implName.Empty() {
-				// Expose refers to own component
-				implComponent = component
-			} else {
-				// Implementation component refers to a child component
-				if !implName.IsChildOf(componentName) {
-					return maskf(InvalidComponentDefinitionError, "invalid expose to component '%s': is not a child of '%s'", implName, componentName)
-				}
-				// Find implementation component
-				var err error
-				implComponent, err = nds.ComponentByName(implName)
-				if err != nil {
-					return maskf(InvalidComponentDefinitionError, "invalid expose to component '%s': does not exists", implName)
-				}
-			}
-
-			// Does the implementation component expose the targeted port?
-			implPort := expose.ImplementationPort()
-			if !implComponent.Ports.contains(implPort) {
-				return maskf(InvalidComponentDefinitionError, "invalid expose to component '%s': does not export port '%s'", implName, implPort)
+		for i, expose := range component.Expose {
+			if err := nds.validateExposeImplementation(componentName, component, expose); err != nil {
+				return mask(diagnosticInField(diagnosticInComponent(err, componentName), fmt.Sprintf("expose[%d]", i)))
 			}
 		}
 
@@ -78,7 +117,7 @@ func (nds ComponentDefinitions) validateExpose() error {
 		for _, expose := range component.Expose {
 			for j := i + 1; j < len(rootComponents); j++ {
 				if rootComponents[j].Expose.contains(expose.Port) {
-					return maskf(InvalidComponentDefinitionError, "port '%s' is exposed by multiple root components", expose.Port)
+					return newDiagnostic(InvalidComponentDefinitionError, "port '%s' is exposed by multiple root components", expose.Port).withField("expose").withValue(expose.Port)
 				}
 			}
 		}
@@ -87,6 +126,40 @@ func (nds ComponentDefinitions) validateExpose() error {
 	return nil
 }
 
+// validateExposeImplementation checks that the implementation of the given
+// expose definition of the given component exports the exposed port.
+func (nds ComponentDefinitions) validateExposeImplementation(componentName ComponentName, component *ComponentDefinition, expose ExposeDefinition) error {
+	// Try to find the implementation component
+	implName := expose.Component
+	var implComponent *ComponentDefinition
+	if implName.Empty() {
+		// Expose refers to own component
+		implComponent = component
+	} else {
+		// Implementation component refers to a child component
+		if !implName.IsChildOf(componentName) {
+			return newDiagnostic(InvalidComponentDefinitionError, "invalid expose to component '%s': is not a child of '%s'", implName, componentName).withValue(implName)
+		}
+		// Find implementation component
+		var err error
+		implComponent, err = nds.ComponentByName(implName)
+		if err != nil {
+			return newDiagnostic(InvalidComponentDefinitionError, "invalid expose to component '%s': does not exists", implName).withValue(implName).withSuggestion(implName.String(), orderedComponentKeys(nds))
+		}
+	}
+
+	// Does the implementation component expose the targeted port?
+	implPort := expose.ImplementationPort()
+	if !implComponent.Ports.contains(implPort) {
+		if other, ok := implComponent.Ports.protocolMismatch(implPort); ok {
+			return newDiagnostic(InvalidComponentDefinitionError, "invalid expose to component '%s': port '%s' does not match protocol of exported port '%s'", implName, implPort, other).withValue(implPort)
+		}
+		return newDiagnostic(InvalidComponentDefinitionError, "invalid expose to component '%s': does not export port '%s'", implName, implPort).withValue(implPort).withFix("export port '%s' in component '%s'", implPort, expose.ImplementationComponentName(componentName)).withSuggestion(implPort.String(), portCandidates(implComponent.Ports, nil))
+	}
+
+	return nil
+}
+
 // String returns the marshalled and ordered string represantion of its own
 // incarnation. It is important to have the string represantion ordered, since
 // we use it to compare two ExposeDefinitions when creating a diff. See diff.go
@@ -109,15 +182,19 @@ func (eds ExposeDefinitions) String() string {
 // validate checks for invalid and duplicate entries
 func (eds ExposeDefinitions) validate() error {
 	for i, ed := range eds {
-		if ed.Port.Empty() {
+		field := fmt.Sprintf("[%d]", i)
+		if ed.Port.Empty() && ed.PortName.Empty() {
 			// Invalid exposed port found
-			return maskf(InvalidComponentDefinitionError, "cannot expose with empty port")
+			return newDiagnostic(InvalidComponentDefinitionError, "cannot expose with empty port").withField(field)
+		}
+		if !ed.TargetPort.Empty() && ed.TargetPort.Protocol != ed.Port.Protocol {
+			return newDiagnostic(InvalidComponentDefinitionError, "protocol of exposed port '%s' does not match target port '%s'", ed.Port, ed.TargetPort).withField(field + ".target_port").withValue(ed.TargetPort)
 		}
 
 		for j := i + 1; j < len(eds); j++ {
 			if eds[j].Port.Equals(ed.Port) {
 				// Duplicate exposed port found
-				return maskf(InvalidComponentDefinitionError, "port '%s' is exposed more than once", ed.Port)
+				return newDiagnostic(InvalidComponentDefinitionError, "port '%s' is exposed more than once", ed.Port).withField(fmt.Sprintf("[%d]", j)).withValue(ed.Port)
 			}
 		}
 	}
@@ -164,6 +241,19 @@ func (ed *ExposeDefinition) ImplementationPort() generictypes.DockerPort {
 	return ed.TargetPort
 }
 
+// implementationPortName returns the name of the port on the component that
+// implements the stable API exposed by this definition, if it is referred to
+// by name.
+func (ed *ExposeDefinition) implementationPortName() PortName {
+	if !ed.TargetPortName.Empty() {
+		return ed.TargetPortName
+	}
+	if ed.TargetPort.Empty() {
+		return ed.PortName
+	}
+	return ""
+}
+
 // resolve resolves the implementation of the given Expose definition in the context of the given
 // component definitions.
 // Resolve returns the name of the component the implements this expose and its implementation port.
@@ -180,6 +270,13 @@ func (ed *ExposeDefinition) Resolve(containingComponentName ComponentName, nds C
 		return "", generictypes.DockerPort{}, mask(err)
 	}
 
+	// Resolve the implementation port, if it is referred to by name
+	if implPortName := ed.implementationPortName(); !implPortName.Empty() {
+		if implPort, err = component.portByName(implPortName); err != nil {
+			return "", generictypes.DockerPort{}, maskf(PortNotFoundError, "component '%s' has no port named '%s'", implName, implPortName)
+		}
+	}
+
 	// Check expose definitions of component
 	if !implName.Equals(containingComponentName) {
 		// Implementation name differs from containing name, so we may recurse into it.
@@ -0,0 +1,172 @@
+package userconfig
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileDefinition describes a small file (e.g. a configuration file) that is
+// placed into the container. The content is either given inline, or as a
+// path relative to the swarm.json.
+type FileDefinition struct {
+	// Path of the file inside the container, e.g. "/etc/nginx/nginx.conf".
+	Path string `json:"path" description:"Path of the file inside the container"`
+
+	// Inline content of the file.
+	Content string `json:"content,omitempty" description:"Content of the file"`
+
+	// Path of a local file, relative to the swarm.json, to take the content from.
+	Source string `json:"source,omitempty" description:"Path of a local file (relative to swarm.json) to take the content from"`
+
+	// File mode in octal notation, e.g. "0644".
+	Mode string `json:"mode,omitempty" description:"File mode in octal notation, e.g. '0644'"`
+}
+
+type FileDefinitions []FileDefinition
+
+// ContentHash returns the hex encoded SHA256 checksum of the inline content
+// of this file. If the content is not inlined, an empty string is returned.
+// Use ServiceDefinition.InlineFiles to inline files given by source.
+func (fd FileDefinition) ContentHash() string {
+	if fd.Content == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(fd.Content)))
+}
+
+// String returns the string represantion of the current incarnation. The
+// content itself is represented by its hash only.
+func (fd FileDefinition) String() string {
+	// A string map is reliable enough for our case, as the JSON implementation
+	// takes care of the order of the provided fields. See
+	// http://play.golang.org/p/U8nDgdga2X
+	m := map[string]string{
+		"path":           fd.Path,
+		"source":         fd.Source,
+		"mode":           fd.Mode,
+		"content-sha256": fd.ContentHash(),
+	}
+
+	raw, err := json.Marshal(m)
+	if err != nil {
+		panic(fmt.Sprintf("%#v\n", mask(err)))
+	}
+
+	return string(raw)
+}
+
+func (fd FileDefinition) validate() error {
+	if fd.Path == "" {
+		return maskf(InvalidFileDefinitionError, "file path must not be empty")
+	}
+	if !path.IsAbs(fd.Path) {
+		return maskf(InvalidFileDefinitionError, "file path '%s' must be absolute", fd.Path)
+	}
+	if strings.HasSuffix(fd.Path, "/") {
+		return maskf(InvalidFileDefinitionError, "file path '%s' must not end with '/'", fd.Path)
+	}
+
+	if fd.Content == "" && fd.Source == "" {
+		return maskf(InvalidFileDefinitionError, "content or source must be set for file '%s'", fd.Path)
+	}
+	if fd.Content != "" && fd.Source != "" {
+		return maskf(InvalidFileDefinitionError, "content and source cannot be set both for file '%s'", fd.Path)
+	}
+
+	if fd.Source != "" {
+		if path.IsAbs(fd.Source) {
+			return maskf(InvalidFileDefinitionError, "source '%s' of file '%s' must be relative to swarm.json", fd.Source, fd.Path)
+		}
+		if clean := path.Clean(fd.Source); clean == ".." || strings.HasPrefix(clean, "../") {
+			return maskf(InvalidFileDefinitionError, "source '%s' of file '%s' must not point outside of the directory of swarm.json", fd.Source, fd.Path)
+		}
+	}
+
+	if fd.Mode != "" {
+		mode, err := strconv.ParseUint(fd.Mode, 8, 32)
+		if err != nil || mode > 07777 {
+			return maskf(InvalidFileDefinitionError, "mode '%s' of file '%s' must be given in octal notation, e.g. '0644'", fd.Mode, fd.Path)
+		}
+	}
+
+	return nil
+}
+
+// validate checks all files for invalid entries and duplicate paths. Files
+// also must not collide with secrets exposed as file.
+func (fds FileDefinitions) validate(secrets SecretDefinitions) error {
+	paths := map[string]string{}
+	for _, sd := range secrets {
+		if sd.Path != "" {
+			paths[normalizeFolder(sd.Path)] = sd.Name
+		}
+	}
+
+	for i, fd := range fds {
+		field := fmt.Sprintf("[%d]", i)
+		if err := fd.validate(); err != nil {
+			return mask(diagnosticInField(err, field))
+		}
+
+		p := path.Clean(fd.Path)
+		if name, ok := paths[p]; ok {
+			if name == "" {
+				return newDiagnostic(InvalidFileDefinitionError, "file '%s' is defined more than once", fd.Path).withField(field + ".path").withValue(fd.Path)
+			}
+			return newDiagnostic(InvalidFileDefinitionError, "file '%s' conflicts with secret '%s'", fd.Path, name).withField(field + ".path").withValue(fd.Path)
+		}
+		paths[p] = ""
+	}
+
+	return nil
+}
+
+// String returns the marshalled and ordered string represantion of its own
+// incarnation. It is important to have the string represantion ordered, since
+// we use it to compare two FileDefinitions when creating a diff. See diff.go
+func (fds FileDefinitions) String() string {
+	list := []string{}
+
+	for _, fd := range fds {
+		list = append(list, fd.String())
+	}
+	sort.Strings(list)
+
+	raw, err := json.Marshal(list)
+	if err != nil {
+		panic(fmt.Sprintf("%#v\n", mask(err)))
+	}
+
+	return string(raw)
+}
+
+// InlineFiles reads the content of all files that are given by source,
+// relative to the given directory, which is expected to be the directory of
+// the swarm.json. The source is replaced by the content afterwards.
+func (sd *ServiceDefinition) InlineFiles(dir string) error {
+	for componentName, componentDef := range sd.Components {
+		for i, fd := range componentDef.Files {
+			if fd.Source == "" {
+				continue
+			}
+
+			raw, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(fd.Source)))
+			if err != nil {
+				return maskf(InvalidFileDefinitionError, "cannot read source '%s' of file '%s' in component '%s': %s", fd.Source, fd.Path, componentName.String(), err.Error())
+			}
+
+			componentDef.Files[i].Content = string(raw)
+			componentDef.Files[i].Source = ""
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,284 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/user-config"
+)
+
+func TestParseFiles(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"web": {
+				"image": "registry/namespace/repository:version",
+				"files": [
+					{ "path": "/etc/nginx/nginx.conf", "source": "conf/nginx.conf" },
+					{ "path": "/etc/motd", "content": "hello world\n", "mode": "0644" }
+				]
+			}
+		}
+	}`)
+
+	var serviceDef userconfig.ServiceDefinition
+	if err := json.Unmarshal(b, &serviceDef); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	files := serviceDef.Components["web"].Files
+	if len(files) != 2 {
+		t.Fatalf("expected two files: %d given", len(files))
+	}
+	if files[0].Path != "/etc/nginx/nginx.conf" || files[0].Source != "conf/nginx.conf" {
+		t.Fatalf("invalid file: %s", files[0].String())
+	}
+	if files[1].Content != "hello world\n" || files[1].Mode != "0644" {
+		t.Fatalf("invalid file: %s", files[1].String())
+	}
+
+	if err := serviceDef.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+}
+
+func TestFilesValidation(t *testing.T) {
+	tests := []struct {
+		Volumes userconfig.VolumeDefinitions
+		Secrets userconfig.SecretDefinitions
+		Files   userconfig.FileDefinitions
+		Valid   bool
+	}{
+		// Valid ones
+		{
+			nil,
+			nil,
+			userconfig.FileDefinitions{{Path: "/etc/app.conf", Content: "debug=true"}},
+			true,
+		},
+		{
+			userconfig.VolumeDefinitions{{Path: "/data", Size: "5 GB"}},
+			userconfig.SecretDefinitions{{Name: "key", Path: "/etc/key.pem"}},
+			userconfig.FileDefinitions{{Path: "/etc/app.conf", Source: "./conf/app.conf", Mode: "600"}},
+			true,
+		},
+
+		// Invalid ones
+		{
+			nil,
+			nil,
+			userconfig.FileDefinitions{{Content: "debug=true"}},
+			false,
+		},
+		{
+			nil,
+			nil,
+			userconfig.FileDefinitions{{Path: "etc/app.conf", Content: "debug=true"}},
+			false,
+		},
+		{
+			nil,
+			nil,
+			userconfig.FileDefinitions{{Path: "/etc/", Content: "debug=true"}},
+			false,
+		},
+		{
+			// Neither content nor source
+			nil,
+			nil,
+			userconfig.FileDefinitions{{Path: "/etc/app.conf"}},
+			false,
+		},
+		{
+			// Both content and source
+			nil,
+			nil,
+			userconfig.FileDefinitions{{Path: "/etc/app.conf", Content: "debug=true", Source: "app.conf"}},
+			false,
+		},
+		{
+			nil,
+			nil,
+			userconfig.FileDefinitions{{Path: "/etc/app.conf", Source: "/home/me/app.conf"}},
+			false,
+		},
+		{
+			nil,
+			nil,
+			userconfig.FileDefinitions{{Path: "/etc/app.conf", Source: "conf/../../app.conf"}},
+			false,
+		},
+		{
+			nil,
+			nil,
+			userconfig.FileDefinitions{{Path: "/etc/app.conf", Content: "debug=true", Mode: "0888"}},
+			false,
+		},
+		{
+			nil,
+			nil,
+			userconfig.FileDefinitions{{Path: "/etc/app.conf", Content: "debug=true", Mode: "rw-r--r--"}},
+			false,
+		},
+		{
+			// Duplicate path
+			nil,
+			nil,
+			userconfig.FileDefinitions{{Path: "/etc/app.conf", Content: "a"}, {Path: "/etc/app.conf", Content: "b"}},
+			false,
+		},
+		{
+			// Clashes with secret
+			nil,
+			userconfig.SecretDefinitions{{Name: "key", Path: "/etc/key.pem"}},
+			userconfig.FileDefinitions{{Path: "/etc/key.pem", Content: "key"}},
+			false,
+		},
+		{
+			// Clashes with volume
+			userconfig.VolumeDefinitions{{Path: "/data", Size: "5 GB"}},
+			nil,
+			userconfig.FileDefinitions{{Path: "/data", Content: "data"}},
+			false,
+		},
+		{
+			// Inside volume
+			userconfig.VolumeDefinitions{{Path: "/data/", Size: "5 GB"}},
+			nil,
+			userconfig.FileDefinitions{{Path: "/data/app.conf", Content: "debug=true"}},
+			false,
+		},
+	}
+
+	for i, test := range tests {
+		def := ExampleDefinition()
+		def.Components["component/a"].Volumes = test.Volumes
+		def.Components["component/a"].Secrets = test.Secrets
+		def.Components["component/a"].Files = test.Files
+
+		err := def.Validate(nil)
+		if test.Valid && err != nil {
+			t.Fatalf("test %d: expected definition to be valid, got error: %#v", i, err)
+		}
+		if !test.Valid && !userconfig.IsInvalidFileDefinition(err) {
+			t.Fatalf("test %d: expected error to be InvalidFileDefinitionError, got: %#v", i, err)
+		}
+	}
+}
+
+func TestFileInNestedVolumes(t *testing.T) {
+	// The file is inside both volumes, the first one is reported every time
+	for i := 0; i < 20; i++ {
+		def := ExampleDefinition()
+		def.Components["component/a"].Volumes = userconfig.VolumeDefinitions{{Path: "/data/cache", Size: "1 GB"}, {Path: "/data", Size: "5 GB"}}
+		def.Components["component/a"].Files = userconfig.FileDefinitions{{Path: "/data/cache/app.conf", Content: "debug=true"}}
+
+		err := def.Validate(nil)
+		if !userconfig.IsInvalidFileDefinition(err) {
+			t.Fatalf("expected error to be InvalidFileDefinitionError, got: %#v", err)
+		}
+		if !strings.Contains(err.Error(), "volume '/data/cache'") {
+			t.Fatalf("expected conflict with volume '/data/cache', got: %v", err)
+		}
+	}
+}
+
+func TestInlineFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "user-config-files")
+	if err != nil {
+		t.Fatalf("creating temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "app.conf"), []byte("debug=true"), 0644); err != nil {
+		t.Fatalf("writing file failed: %v", err)
+	}
+
+	def := ExampleDefinition()
+	def.Components["component/a"].Files = userconfig.FileDefinitions{{Path: "/etc/app.conf", Source: "app.conf"}}
+
+	if err := def.InlineFiles(dir); err != nil {
+		t.Fatalf("inlining files failed: %#v", err)
+	}
+
+	fd := def.Components["component/a"].Files[0]
+	if fd.Content != "debug=true" || fd.Source != "" {
+		t.Fatalf("file not inlined: %#v", fd)
+	}
+
+	def.Components["component/a"].Files = userconfig.FileDefinitions{{Path: "/etc/app.conf", Source: "missing.conf"}}
+	if err := def.InlineFiles(dir); !userconfig.IsInvalidFileDefinition(err) {
+		t.Fatalf("expected error to be InvalidFileDefinitionError, got: %#v", err)
+	}
+}
+
+func TestFilesDiff(t *testing.T) {
+	oldDef := ExampleDefinition()
+	oldDef.Components["component/a"].Files = userconfig.FileDefinitions{{Path: "/etc/app.conf", Content: "debug=false"}}
+	newDef := ExampleDefinition()
+	newDef.Components["component/a"].Files = userconfig.FileDefinitions{{Path: "/etc/app.conf", Content: "debug=true"}}
+
+	diffInfos := userconfig.DiffInfosByType(userconfig.ServiceDiff(oldDef, newDef), userconfig.DiffTypeComponentFilesUpdated)
+	if len(diffInfos) != 1 {
+		t.Fatalf("expected one diff: %#v", diffInfos)
+	}
+	if strings.Contains(diffInfos[0].New, "debug") {
+		t.Fatalf("expected content not to show up in diff, got: %s", diffInfos[0].New)
+	}
+	if !strings.Contains(diffInfos[0].New, newDef.Components["component/a"].Files[0].ContentHash()) {
+		t.Fatalf("expected content hash in diff, got: %s", diffInfos[0].New)
+	}
+
+	diffInfos = userconfig.DiffInfosByType(userconfig.ServiceDiff(newDef, newDef), userconfig.DiffTypeComponentFilesUpdated)
+	if len(diffInfos) != 0 {
+		t.Fatalf("expected no diff: %#v", diffInfos)
+	}
+}
+
+func TestFilesDiffSourceContent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "user-config-files")
+	if err != nil {
+		t.Fatalf("creating temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	load := func(content string) userconfig.ServiceDefinition {
+		if err := ioutil.WriteFile(filepath.Join(dir, "nginx.conf"), []byte(content), 0644); err != nil {
+			t.Fatalf("writing file failed: %v", err)
+		}
+		def := ExampleDefinition()
+		def.Components["component/a"].Files = userconfig.FileDefinitions{{Path: "/etc/nginx/nginx.conf", Source: "nginx.conf"}}
+		if err := def.InlineFiles(dir); err != nil {
+			t.Fatalf("inlining files failed: %#v", err)
+		}
+		return def
+	}
+
+	oldDef := load("worker_processes 1;")
+	newDef := load("worker_processes 4;")
+	diffInfos := userconfig.DiffInfosByType(userconfig.ServiceDiff(oldDef, newDef), userconfig.DiffTypeComponentFilesUpdated)
+	if len(diffInfos) != 1 {
+		t.Fatalf("expected one diff for changed source content: %#v", diffInfos)
+	}
+
+	// Without inlining files are compared by source, identical definitions
+	// never differ
+	notInlined := ExampleDefinition()
+	notInlined.Components["component/a"].Files = userconfig.FileDefinitions{{Path: "/etc/nginx/nginx.conf", Source: "nginx.conf", Mode: "0644"}}
+	if diffInfos := userconfig.ServiceDiff(notInlined, notInlined); len(diffInfos) != 0 {
+		t.Fatalf("expected no diff for identical definitions: %#v", diffInfos)
+	}
+	if diffInfos := userconfig.ServiceDiff(oldDef, oldDef); len(diffInfos) != 0 {
+		t.Fatalf("expected no diff for identical definitions: %#v", diffInfos)
+	}
+
+	otherSource := ExampleDefinition()
+	otherSource.Components["component/a"].Files = userconfig.FileDefinitions{{Path: "/etc/nginx/nginx.conf", Source: "nginx-prod.conf", Mode: "0644"}}
+	diffInfos = userconfig.DiffInfosByType(userconfig.ServiceDiff(notInlined, otherSource), userconfig.DiffTypeComponentFilesUpdated)
+	if len(diffInfos) != 1 {
+		t.Fatalf("expected one diff for changed source: %#v", diffInfos)
+	}
+}
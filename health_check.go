@@ -0,0 +1,184 @@
+package userconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/generic-types-go"
+)
+
+// HTTPHealthCheck checks the health of a component by sending a HTTP GET
+// request to one of its ports.
+type HTTPHealthCheck struct {
+	// Path of the request, e.g. "/healthz".
+	Path string `json:"path" description:"Path to send the HTTP request to, e.g. /healthz"`
+
+	// Port of the component to send the request to. Must be one of the ports
+	// of the component.
+	Port generictypes.DockerPort `json:"port" description:"Port of the component to send the HTTP request to"`
+
+	// Status code expected in the response. If empty, any 2xx or 3xx status code is accepted.
+	Status int `json:"status,omitempty" description:"Expected HTTP status code. If empty, any 2xx or 3xx status code is accepted"`
+}
+
+// TCPHealthCheck checks the health of a component by opening a TCP connection
+// to one of its ports.
+type TCPHealthCheck struct {
+	// Port of the component to connect to. Must be one of the ports of the
+	// component.
+	Port generictypes.DockerPort `json:"port" description:"Port of the component to open a TCP connection to"`
+}
+
+// ExecHealthCheck checks the health of a component by running a command
+// inside its container. A zero exit code means healthy.
+type ExecHealthCheck struct {
+	Command []string `json:"command" description:"Command to run inside the container. A zero exit code means healthy"`
+}
+
+type HealthCheckDefinition struct {
+	HTTP *HTTPHealthCheck `json:"http,omitempty" description:"Check the component using a HTTP request"`
+	TCP  *TCPHealthCheck  `json:"tcp,omitempty" description:"Check the component by opening a TCP connection"`
+	Exec *ExecHealthCheck `json:"exec,omitempty" description:"Check the component by running a command inside its container"`
+
+	// Time between two checks, e.g. "10s".
+	Interval Duration `json:"interval,omitempty" description:"Time between two checks, e.g. '10s'"`
+
+	// Time after which a single check is considered failed, e.g. "2s".
+	Timeout Duration `json:"timeout,omitempty" description:"Time after which a single check is considered failed, e.g. '2s'"`
+
+	// Number of consecutive successful checks before a component is considered healthy.
+	HealthyThreshold int `json:"healthy-threshold,omitempty" description:"Number of consecutive successful checks before the component is considered healthy"`
+
+	// Number of consecutive failed checks before a component is considered unhealthy.
+	UnhealthyThreshold int `json:"unhealthy-threshold,omitempty" description:"Number of consecutive failed checks before the component is considered unhealthy"`
+}
+
+// String returns the marshalled string represantion of its own incarnation.
+// We use it to compare two HealthCheckDefinitions when creating a diff. See
+// diff.go
+func (hcd *HealthCheckDefinition) String() string {
+	if hcd == nil {
+		return ""
+	}
+
+	raw, err := json.Marshal(hcd)
+	if err != nil {
+		panic(fmt.Sprintf("%#v\n", mask(err)))
+	}
+
+	return string(raw)
+}
+
+// validate checks that exactly one kind of check is configured, that all
+// checked ports are exported by the component and that the timing settings
+// are sane.
+func (hcd *HealthCheckDefinition) validate(exportedPorts PortDefinitions) error {
+	kinds := 0
+	if hcd.HTTP != nil {
+		kinds++
+	}
+	if hcd.TCP != nil {
+		kinds++
+	}
+	if hcd.Exec != nil {
+		kinds++
+	}
+	if kinds != 1 {
+		return newDiagnostic(InvalidHealthCheckDefinitionError, "exactly one of 'http', 'tcp' or 'exec' must be set").withFix("set one of http, tcp or exec")
+	}
+
+	if hcd.HTTP != nil {
+		if !strings.HasPrefix(hcd.HTTP.Path, "/") {
+			return newDiagnostic(InvalidHealthCheckDefinitionError, "http path '%s' must start with '/'", hcd.HTTP.Path).withField("http.path").withValue(hcd.HTTP.Path)
+		}
+		if hcd.HTTP.Port.Protocol != generictypes.ProtocolTCP {
+			return newDiagnostic(InvalidHealthCheckDefinitionError, "http port '%s' must use protocol '%s'", hcd.HTTP.Port, generictypes.ProtocolTCP).withField("http.port").withValue(hcd.HTTP.Port)
+		}
+		if !exportedPorts.contains(hcd.HTTP.Port) {
+			return newDiagnostic(InvalidHealthCheckDefinitionError, "http port '%s' must be exported", hcd.HTTP.Port).withField("http.port").withValue(hcd.HTTP.Port).withFix("add '%s' to ports", hcd.HTTP.Port)
+		}
+		if hcd.HTTP.Status != 0 && (hcd.HTTP.Status < 100 || hcd.HTTP.Status > 599) {
+			return newDiagnostic(InvalidHealthCheckDefinitionError, "http status '%d' is not a valid HTTP status code", hcd.HTTP.Status).withField("http.status").withValue(hcd.HTTP.Status)
+		}
+	}
+
+	if hcd.TCP != nil {
+		if hcd.TCP.Port.Protocol != generictypes.ProtocolTCP {
+			return newDiagnostic(InvalidHealthCheckDefinitionError, "tcp port '%s' must use protocol '%s'", hcd.TCP.Port, generictypes.ProtocolTCP).withField("tcp.port").withValue(hcd.TCP.Port)
+		}
+		if !exportedPorts.contains(hcd.TCP.Port) {
+			return newDiagnostic(InvalidHealthCheckDefinitionError, "tcp port '%s' must be exported", hcd.TCP.Port).withField("tcp.port").withValue(hcd.TCP.Port).withFix("add '%s' to ports", hcd.TCP.Port)
+		}
+	}
+
+	if hcd.Exec != nil {
+		if len(hcd.Exec.Command) == 0 {
+			return newDiagnostic(InvalidHealthCheckDefinitionError, "exec command must not be empty").withField("exec.command")
+		}
+	}
+
+	if !hcd.Interval.IsEmpty() && !hcd.Interval.Valid() {
+		return newDiagnostic(InvalidHealthCheckDefinitionError, "invalid interval '%s'", hcd.Interval).withField("interval").withValue(hcd.Interval)
+	}
+
+	if !hcd.Timeout.IsEmpty() && !hcd.Timeout.Valid() {
+		return newDiagnostic(InvalidHealthCheckDefinitionError, "invalid timeout '%s'", hcd.Timeout).withField("timeout").withValue(hcd.Timeout)
+	}
+
+	if !hcd.Interval.IsEmpty() && !hcd.Timeout.IsEmpty() {
+		interval, _ := hcd.Interval.Duration()
+		timeout, _ := hcd.Timeout.Duration()
+		if timeout > interval {
+			return newDiagnostic(InvalidHealthCheckDefinitionError, "timeout '%s' cannot be greater than interval '%s'", hcd.Timeout, hcd.Interval).withField("timeout").withValue(hcd.Timeout)
+		}
+	}
+
+	if hcd.HealthyThreshold < 0 {
+		return newDiagnostic(InvalidHealthCheckDefinitionError, "healthy-threshold '%d' cannot be negative", hcd.HealthyThreshold).withField("healthy-threshold").withValue(hcd.HealthyThreshold)
+	}
+
+	if hcd.UnhealthyThreshold < 0 {
+		return newDiagnostic(InvalidHealthCheckDefinitionError, "unhealthy-threshold '%d' cannot be negative", hcd.UnhealthyThreshold).withField("unhealthy-threshold").withValue(hcd.UnhealthyThreshold)
+	}
+
+	return nil
+}
+
+func (hcd *HealthCheckDefinition) setDefaults(valCtx *ValidationContext) {
+	if hcd.Interval.IsEmpty() {
+		hcd.Interval = valCtx.HealthCheckInterval
+	}
+
+	if hcd.Timeout.IsEmpty() {
+		hcd.Timeout = valCtx.HealthCheckTimeout
+	}
+
+	if hcd.HealthyThreshold == 0 {
+		hcd.HealthyThreshold = valCtx.HealthCheckHealthyThreshold
+	}
+
+	if hcd.UnhealthyThreshold == 0 {
+		hcd.UnhealthyThreshold = valCtx.HealthCheckUnhealthyThreshold
+	}
+}
+
+func (hcd *HealthCheckDefinition) hideDefaults(valCtx *ValidationContext) *HealthCheckDefinition {
+	if hcd.Interval.Equals(valCtx.HealthCheckInterval) {
+		hcd.Interval = ""
+	}
+
+	if hcd.Timeout.Equals(valCtx.HealthCheckTimeout) {
+		hcd.Timeout = ""
+	}
+
+	if hcd.HealthyThreshold == valCtx.HealthCheckHealthyThreshold {
+		hcd.HealthyThreshold = 0
+	}
+
+	if hcd.UnhealthyThreshold == valCtx.HealthCheckUnhealthyThreshold {
+		hcd.UnhealthyThreshold = 0
+	}
+
+	return hcd
+}
@@ -0,0 +1,212 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/giantswarm/generic-types-go"
+	"github.com/giantswarm/user-config"
+)
+
+func TestParseHealthCheck(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"api": {
+				"image": "registry/namespace/repository:version",
+				"ports": [ "8080/tcp" ],
+				"healthcheck": {
+					"http": { "path": "/healthz", "port": "8080/tcp", "status": 200 },
+					"interval": "10s",
+					"timeout": "2s",
+					"healthy-threshold": 2,
+					"unhealthy-threshold": 3
+				}
+			}
+		}
+	}`)
+
+	var serviceDef userconfig.ServiceDefinition
+	if err := json.Unmarshal(b, &serviceDef); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	hc := serviceDef.Components["api"].HealthCheck
+	if hc == nil || hc.HTTP == nil {
+		t.Fatalf("expected http health check to be parsed")
+	}
+	if hc.HTTP.Path != "/healthz" {
+		t.Fatalf("invalid path: %s", hc.HTTP.Path)
+	}
+	if hc.HTTP.Port.String() != "8080/tcp" {
+		t.Fatalf("invalid port: %s", hc.HTTP.Port.String())
+	}
+	if hc.Interval != "10s" || hc.Timeout != "2s" {
+		t.Fatalf("invalid timing settings: %s, %s", hc.Interval, hc.Timeout)
+	}
+
+	if err := serviceDef.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+}
+
+func TestHealthCheckValidation(t *testing.T) {
+	tests := []struct {
+		HealthCheck *userconfig.HealthCheckDefinition
+		Valid       bool
+	}{
+		// Valid ones
+		{
+			&userconfig.HealthCheckDefinition{
+				HTTP: &userconfig.HTTPHealthCheck{Path: "/", Port: generictypes.MustParseDockerPort("80/tcp")},
+			},
+			true,
+		},
+		{
+			&userconfig.HealthCheckDefinition{
+				TCP:      &userconfig.TCPHealthCheck{Port: generictypes.MustParseDockerPort("80/tcp")},
+				Interval: "30",
+				Timeout:  "500ms",
+			},
+			true,
+		},
+		{
+			&userconfig.HealthCheckDefinition{
+				Exec: &userconfig.ExecHealthCheck{Command: []string{"pg_isready"}},
+			},
+			true,
+		},
+
+		// Invalid ones
+		{
+			// No kind of check
+			&userconfig.HealthCheckDefinition{Interval: "10s"},
+			false,
+		},
+		{
+			// Multiple kinds of check
+			&userconfig.HealthCheckDefinition{
+				TCP:  &userconfig.TCPHealthCheck{Port: generictypes.MustParseDockerPort("80/tcp")},
+				Exec: &userconfig.ExecHealthCheck{Command: []string{"true"}},
+			},
+			false,
+		},
+		{
+			// Port not exported
+			&userconfig.HealthCheckDefinition{
+				HTTP: &userconfig.HTTPHealthCheck{Path: "/", Port: generictypes.MustParseDockerPort("8080/tcp")},
+			},
+			false,
+		},
+		{
+			// Port not exported
+			&userconfig.HealthCheckDefinition{
+				TCP: &userconfig.TCPHealthCheck{Port: generictypes.MustParseDockerPort("81/tcp")},
+			},
+			false,
+		},
+		{
+			// Relative path
+			&userconfig.HealthCheckDefinition{
+				HTTP: &userconfig.HTTPHealthCheck{Path: "healthz", Port: generictypes.MustParseDockerPort("80/tcp")},
+			},
+			false,
+		},
+		{
+			// Invalid status code
+			&userconfig.HealthCheckDefinition{
+				HTTP: &userconfig.HTTPHealthCheck{Path: "/", Port: generictypes.MustParseDockerPort("80/tcp"), Status: 1000},
+			},
+			false,
+		},
+		{
+			// Empty command
+			&userconfig.HealthCheckDefinition{
+				Exec: &userconfig.ExecHealthCheck{},
+			},
+			false,
+		},
+		{
+			// Invalid interval
+			&userconfig.HealthCheckDefinition{
+				Exec:     &userconfig.ExecHealthCheck{Command: []string{"true"}},
+				Interval: "often",
+			},
+			false,
+		},
+		{
+			// Timeout greater than interval
+			&userconfig.HealthCheckDefinition{
+				Exec:     &userconfig.ExecHealthCheck{Command: []string{"true"}},
+				Interval: "5s",
+				Timeout:  "1m",
+			},
+			false,
+		},
+		{
+			// Negative threshold
+			&userconfig.HealthCheckDefinition{
+				Exec:               &userconfig.ExecHealthCheck{Command: []string{"true"}},
+				UnhealthyThreshold: -1,
+			},
+			false,
+		},
+	}
+
+	for i, test := range tests {
+		def := ExampleDefinition()
+		def.Components["component/a"].HealthCheck = test.HealthCheck
+
+		err := def.Validate(nil)
+		if test.Valid && err != nil {
+			t.Fatalf("test %d: expected definition to be valid, got error: %#v", i, err)
+		}
+		if !test.Valid && !userconfig.IsInvalidHealthCheckDefinition(err) {
+			t.Fatalf("test %d: expected error to be InvalidHealthCheckDefinitionError, got: %#v", i, err)
+		}
+	}
+}
+
+func TestHealthCheckDefaults(t *testing.T) {
+	a := ExampleDefinition()
+	a.Components["component/a"].HealthCheck = &userconfig.HealthCheckDefinition{
+		TCP:     &userconfig.TCPHealthCheck{Port: generictypes.MustParseDockerPort("80/tcp")},
+		Timeout: "1s",
+	}
+
+	valCtx := NewValidationContext()
+	valCtx.HealthCheckInterval = "10s"
+	valCtx.HealthCheckTimeout = "2s"
+	valCtx.HealthCheckUnhealthyThreshold = 3
+
+	if err := a.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+
+	hc := a.Components["component/a"].HealthCheck
+	if hc.Interval != valCtx.HealthCheckInterval {
+		t.Fatalf("expected default interval to be '%s', got '%s'", valCtx.HealthCheckInterval, hc.Interval)
+	}
+	if hc.Timeout != "1s" {
+		t.Fatalf("expected explicit timeout to be kept, got '%s'", hc.Timeout)
+	}
+	if hc.UnhealthyThreshold != valCtx.HealthCheckUnhealthyThreshold {
+		t.Fatalf("expected default unhealthy-threshold to be '%d', got '%d'", valCtx.HealthCheckUnhealthyThreshold, hc.UnhealthyThreshold)
+	}
+
+	if err := a.Validate(valCtx); err != nil {
+		t.Fatalf("validating service failed: %#v", err)
+	}
+
+	b, err := a.HideDefaults(valCtx)
+	if err != nil {
+		t.Fatalf("hiding defaults failed: %#v", err)
+	}
+
+	hc = b.Components["component/a"].HealthCheck
+	if hc.Interval != "" || hc.UnhealthyThreshold != 0 {
+		t.Fatalf("defaults not hidden: %s", hc.String())
+	}
+	if hc.Timeout != "1s" {
+		t.Fatalf("expected explicit timeout to be kept, got '%s'", hc.Timeout)
+	}
+}
@@ -1,21 +1,88 @@
 package userconfig
 
 import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+
 	"github.com/giantswarm/generic-types-go"
 )
 
+const (
+	// defaultRegistry is used to match registry policies against images that
+	// do not specify a registry.
+	defaultRegistry = "docker.io"
+
+	latestTag = "latest"
+)
+
+var digestRegExp = regexp.MustCompile("^sha256:[a-f0-9]{64}$")
+
 type ImageDefinition struct {
 	generictypes.DockerImage
+
+	// Digest the image is pinned to, e.g. "sha256:4f3c...".
+	Digest string
 }
 
-func MustParseImageDefinition(id string) *ImageDefinition {
+func ParseImageDefinition(id string) (*ImageDefinition, error) {
+	var digest string
+	if i := strings.Index(id, "@"); i >= 0 {
+		id, digest = id[:i], id[i+1:]
+		if !digestRegExp.MatchString(digest) {
+			return nil, maskf(InvalidImageDefinitionError, "invalid image digest '%s'", digest)
+		}
+	}
+
+	dockerImage, err := generictypes.ParseDockerImage(id)
+	if err != nil {
+		return nil, mask(err)
+	}
+
 	return &ImageDefinition{
-		generictypes.MustParseDockerImage(id),
+		DockerImage: dockerImage,
+		Digest:      digest,
+	}, nil
+}
+
+func MustParseImageDefinition(id string) *ImageDefinition {
+	imageDef, err := ParseImageDefinition(id)
+	if err != nil {
+		panic(err)
 	}
+	return imageDef
+}
+
+// String returns the image as given, including the digest if any.
+func (id ImageDefinition) String() string {
+	if id.Digest == "" {
+		return id.DockerImage.String()
+	}
+	return id.DockerImage.String() + "@" + id.Digest
+}
+
+func (id ImageDefinition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.String())
+}
+
+func (id *ImageDefinition) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return mask(err)
+	}
+
+	imageDef, err := ParseImageDefinition(s)
+	if err != nil {
+		return mask(err)
+	}
+
+	*id = *imageDef
+	return nil
 }
 
 func (id ImageDefinition) GenericDockerImage() generictypes.DockerImage {
-	return generictypes.MustParseDockerImage(id.String())
+	return generictypes.MustParseDockerImage(id.DockerImage.String())
 }
 
 func (id ImageDefinition) Validate(valCtx *ValidationContext) error {
@@ -24,7 +91,53 @@ func (id ImageDefinition) Validate(valCtx *ValidationContext) error {
 	}
 
 	if id.isGSRegistry(valCtx) && id.Namespace != valCtx.Org {
-		return maskf(InvalidImageDefinitionError, "image namespace '%s' must match organization '%s'", id.Namespace, valCtx.Org)
+		return imagePolicyViolationf(ImageRuleRestrictedNamespace, "", id, "image namespace '%s' must match organization '%s'", id.Namespace, valCtx.Org)
+	}
+
+	if err := id.validatePolicies(valCtx); err != nil {
+		return mask(err)
+	}
+
+	if err := id.validateRegistryPolicies(valCtx); err != nil {
+		return mask(err)
+	}
+
+	return nil
+}
+
+// validatePolicies checks the tag, digest and registry policies of the given
+// validation context.
+func (id ImageDefinition) validatePolicies(valCtx *ValidationContext) error {
+	if valCtx.RequireImageTag && id.Version == "" {
+		return imagePolicyViolationf(ImageRuleTagRequired, "", id, "image '%s' must have a tag", id.String())
+	}
+
+	if valCtx.RejectLatestTag && (id.Version == latestTag || (id.Version == "" && id.Digest == "")) {
+		return imagePolicyViolationf(ImageRuleLatestTag, "", id, "image '%s' must not use the '%s' tag", id.String(), latestTag)
+	}
+
+	if valCtx.RequireImageDigest && id.Digest == "" {
+		return imagePolicyViolationf(ImageRuleDigestRequired, "", id, "image '%s' must be pinned to a digest", id.String())
+	}
+
+	registry := id.registry()
+	for _, pattern := range valCtx.DeniedRegistries {
+		if matchRegistry(pattern, registry) {
+			return imagePolicyViolationf(ImageRuleRegistryDenied, "", id, "registry '%s' of image '%s' is not allowed", registry, id.String())
+		}
+	}
+
+	if len(valCtx.AllowedRegistries) > 0 {
+		allowed := false
+		for _, pattern := range valCtx.AllowedRegistries {
+			if matchRegistry(pattern, registry) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return imagePolicyViolationf(ImageRuleRegistryNotAllowed, "", id, "registry '%s' of image '%s' is not allowed", registry, id.String())
+		}
 	}
 
 	return nil
@@ -38,3 +151,19 @@ func (id ImageDefinition) isGSRegistry(valCtx *ValidationContext) bool {
 	}
 	return false
 }
+
+// registry returns the registry of this image, falling back to the default
+// registry.
+func (id ImageDefinition) registry() string {
+	if id.Registry == "" {
+		return defaultRegistry
+	}
+	return id.Registry
+}
+
+// matchRegistry returns true if the given registry matches the given shell
+// pattern, e.g. "*.giantswarm.io".
+func matchRegistry(pattern, registry string) bool {
+	matched, err := path.Match(pattern, registry)
+	return err == nil && matched
+}
@@ -0,0 +1,49 @@
+package userconfig
+
+import (
+	"github.com/giantswarm/generic-types-go"
+)
+
+// ImageDigestResolver resolves the digest a tagged image currently points to,
+// e.g. by asking its registry.
+type ImageDigestResolver interface {
+	// ResolveDigest returns the digest of the given image, e.g. "sha256:4f3c...".
+	ResolveDigest(image generictypes.DockerImage) (string, error)
+}
+
+// InMemoryDigestResolver is an ImageDigestResolver that looks up digests in a
+// map of images to digests, e.g. "registry/namespace/repository:1.0" =>
+// "sha256:4f3c...". It is meant to be used in tests.
+type InMemoryDigestResolver map[string]string
+
+func (r InMemoryDigestResolver) ResolveDigest(image generictypes.DockerImage) (string, error) {
+	digest, ok := r[image.String()]
+	if !ok {
+		return "", maskf(ImageDigestNotFoundError, "no digest for image '%s'", image.String())
+	}
+
+	return digest, nil
+}
+
+// PinImageDigests pins the image of every component, that is not already
+// pinned, to the digest its tag currently points to. The tag is kept for
+// readability.
+func (sd *ServiceDefinition) PinImageDigests(resolver ImageDigestResolver) error {
+	for componentName, componentDef := range sd.Components {
+		if componentDef.Image == nil || componentDef.Image.Digest != "" {
+			continue
+		}
+
+		digest, err := resolver.ResolveDigest(componentDef.Image.DockerImage)
+		if err != nil {
+			return mask(err)
+		}
+		if !digestRegExp.MatchString(digest) {
+			return maskf(InvalidImageDefinitionError, "invalid digest '%s' resolved for image of component '%s'", digest, componentName.String())
+		}
+
+		componentDef.Image.Digest = digest
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package userconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rules of image policies, as reported by ImagePolicyViolation.
+const (
+	ImageRuleRestrictedNamespace = "restricted-namespace"
+	ImageRuleTagRequired         = "tag-required"
+	ImageRuleLatestTag           = "latest-tag"
+	ImageRuleDigestRequired      = "digest-required"
+	ImageRuleRegistryDenied      = "registry-denied"
+	ImageRuleRegistryNotAllowed  = "registry-not-allowed"
+	ImageRuleNamespaceNotAllowed = "namespace-not-allowed"
+)
+
+// orgPlaceholder is replaced by the organization of the validation context
+// when matching namespaces of a RegistryPolicy.
+const orgPlaceholder = "{org}"
+
+// dockerHubOfficialNamespace is the namespace of official images on the
+// default registry, e.g. "nginx" is "library/nginx".
+const dockerHubOfficialNamespace = "library"
+
+// RegistryPolicy describes which images are allowed for registries that
+// match Registry.
+type RegistryPolicy struct {
+	// Name of this policy, reported when it rejects an image.
+	Name string
+
+	// Pattern of the registries this policy applies to, e.g. "docker.io" or
+	// "*.giantswarm.io".
+	Registry string
+
+	// If true, images of matching registries are rejected entirely.
+	Deny bool
+
+	// Patterns of the allowed namespaces, e.g. "library", "{org}" or
+	// "{org}_shared". If empty, all namespaces are allowed.
+	Namespaces []string
+}
+
+// String returns the name of this policy, or the registry pattern if the
+// name is not set.
+func (rp RegistryPolicy) String() string {
+	if rp.Name != "" {
+		return rp.Name
+	}
+	return rp.Registry
+}
+
+func (rp RegistryPolicy) allowsNamespace(namespace, org string) bool {
+	if len(rp.Namespaces) == 0 {
+		return true
+	}
+	for _, pattern := range rp.Namespaces {
+		if matchRegistry(strings.Replace(pattern, orgPlaceholder, org, -1), namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// ImagePolicyViolation describes which rule of an image policy rejected an
+// image. It is returned as underlying error of an InvalidImageDefinitionError.
+// Use ImagePolicyViolationOf to get it from an error.
+type ImagePolicyViolation struct {
+	// Rule that fired, e.g. ImageRuleRegistryDenied.
+	Rule string
+
+	// Name of the RegistryPolicy that fired, if any.
+	Policy string
+
+	// Image that was rejected.
+	Image string
+
+	Message string
+}
+
+func (v *ImagePolicyViolation) Error() string {
+	return v.Message
+}
+
+// ImagePolicyViolationOf returns the image policy violation the given error
+// was caused by, if any.
+func ImagePolicyViolationOf(err error) (*ImagePolicyViolation, bool) {
+	for err != nil {
+		if v, ok := err.(*ImagePolicyViolation); ok {
+			return v, true
+		}
+		w, ok := err.(interface {
+			Underlying() error
+		})
+		if !ok {
+			return nil, false
+		}
+		err = w.Underlying()
+	}
+	return nil, false
+}
+
+// imagePolicyViolationf returns a Diagnostic with cause
+// InvalidImageDefinitionError that carries an ImagePolicyViolation.
+func imagePolicyViolationf(rule, policy string, id ImageDefinition, f string, a ...interface{}) error {
+	v := &ImagePolicyViolation{
+		Rule:    rule,
+		Policy:  policy,
+		Image:   id.String(),
+		Message: fmt.Sprintf(f, a...),
+	}
+	return newDiagnostic(InvalidImageDefinitionError, "").withValue(v.Image).withUnderlying(v)
+}
+
+// validateRegistryPolicies checks the image against the first registry
+// policy matching its registry. If policies are given, but none matches,
+// the image is rejected.
+func (id ImageDefinition) validateRegistryPolicies(valCtx *ValidationContext) error {
+	if len(valCtx.RegistryPolicies) == 0 {
+		return nil
+	}
+
+	registry := id.registry()
+	namespace := id.Namespace
+	if namespace == "" && registry == defaultRegistry {
+		namespace = dockerHubOfficialNamespace
+	}
+
+	for _, policy := range valCtx.RegistryPolicies {
+		if !matchRegistry(policy.Registry, registry) {
+			continue
+		}
+
+		if policy.Deny {
+			return imagePolicyViolationf(ImageRuleRegistryDenied, policy.String(), id, "registry '%s' of image '%s' is denied by policy '%s'", registry, id.String(), policy.String())
+		}
+		if !policy.allowsNamespace(namespace, valCtx.Org) {
+			return imagePolicyViolationf(ImageRuleNamespaceNotAllowed, policy.String(), id, "namespace '%s' of image '%s' is not allowed by policy '%s'", namespace, id.String(), policy.String())
+		}
+		return nil
+	}
+
+	return imagePolicyViolationf(ImageRuleRegistryNotAllowed, "", id, "registry '%s' of image '%s' is not covered by any registry policy", registry, id.String())
+}
@@ -0,0 +1,77 @@
+package userconfig_test
+
+import (
+	"testing"
+
+	"github.com/giantswarm/user-config"
+)
+
+func TestRegistryPolicies(t *testing.T) {
+	valCtx := &userconfig.ValidationContext{
+		Org: "myorg",
+		RegistryPolicies: []userconfig.RegistryPolicy{
+			{Name: "official-images-only", Registry: "docker.io", Namespaces: []string{"library"}},
+			{Name: "own-namespaces", Registry: "registry.private.giantswarm.io", Namespaces: []string{"{org}", "{org}_shared"}},
+			{Name: "no-quay", Registry: "quay.io", Deny: true},
+		},
+	}
+
+	tests := []struct {
+		Image  string
+		Rule   string
+		Policy string
+	}{
+		// Valid ones
+		{"nginx:1.9", "", ""},
+		{"docker.io/library/nginx:1.9", "", ""},
+		{"registry.private.giantswarm.io/myorg/foo:1.0", "", ""},
+		{"registry.private.giantswarm.io/myorg_shared/foo:1.0", "", ""},
+
+		// Invalid ones
+		{"someone/nginx:1.9", userconfig.ImageRuleNamespaceNotAllowed, "official-images-only"},
+		{"registry.private.giantswarm.io/otherorg/foo:1.0", userconfig.ImageRuleNamespaceNotAllowed, "own-namespaces"},
+		{"quay.io/myorg/foo:1.0", userconfig.ImageRuleRegistryDenied, "no-quay"},
+		{"gcr.io/myorg/foo:1.0", userconfig.ImageRuleRegistryNotAllowed, ""},
+	}
+
+	for i, test := range tests {
+		id := userconfig.MustParseImageDefinition(test.Image)
+		err := id.Validate(valCtx)
+		if test.Rule == "" {
+			if err != nil {
+				t.Fatalf("test %d: expected image to be valid, got error: %#v", i, err)
+			}
+			continue
+		}
+
+		if !userconfig.IsInvalidImageDefinition(err) {
+			t.Fatalf("test %d: expected error to be InvalidImageDefinitionError, got: %#v", i, err)
+		}
+		v, ok := userconfig.ImagePolicyViolationOf(err)
+		if !ok {
+			t.Fatalf("test %d: expected error to carry a policy violation, got: %#v", i, err)
+		}
+		if v.Rule != test.Rule || v.Policy != test.Policy || v.Image != test.Image {
+			t.Fatalf("test %d: unexpected violation: %#v", i, v)
+		}
+		if err.Error() != v.Message {
+			t.Fatalf("test %d: expected error message '%s', got '%s'", i, v.Message, err.Error())
+		}
+	}
+}
+
+func TestImagePolicyViolationFromServiceValidation(t *testing.T) {
+	def := ExampleDefinition()
+	valCtx := NewValidationContext()
+	valCtx.RegistryPolicies = []userconfig.RegistryPolicy{
+		{Name: "giantswarm-only", Registry: "registry.giantswarm.io", Namespaces: []string{"giantswarm"}},
+	}
+
+	err := def.Validate(valCtx)
+	if !userconfig.IsInvalidImageDefinition(err) {
+		t.Fatalf("expected error to be InvalidImageDefinitionError, got: %#v", err)
+	}
+	if v, ok := userconfig.ImagePolicyViolationOf(err); !ok || v.Policy != "giantswarm-only" {
+		t.Fatalf("expected violation of policy 'giantswarm-only', got: %#v", v)
+	}
+}
@@ -100,3 +100,96 @@ func TestImageInvalidOrgWithPrivateRegistry(t *testing.T) {
 		t.Fatalf("expected error to be InvalidImageDefinitionError")
 	}
 }
+
+func TestParseImageDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+
+	id, err := userconfig.ParseImageDefinition("registry.giantswarm.io/myorg/foo:1.0@" + digest)
+	if err != nil {
+		t.Fatalf("parsing image failed: %#v", err)
+	}
+	if id.Version != "1.0" || id.Digest != digest {
+		t.Fatalf("invalid image: %#v", id)
+	}
+	if id.String() != "registry.giantswarm.io/myorg/foo:1.0@"+digest {
+		t.Fatalf("invalid image string: %s", id.String())
+	}
+	if id.GenericDockerImage().String() != "registry.giantswarm.io/myorg/foo:1.0" {
+		t.Fatalf("invalid docker image: %s", id.GenericDockerImage().String())
+	}
+
+	raw, err := json.Marshal(id)
+	if err != nil {
+		t.Fatalf("marshaling image failed: %#v", err)
+	}
+	var parsed userconfig.ImageDefinition
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		t.Fatalf("unmarshaling image failed: %#v", err)
+	}
+	if parsed.String() != id.String() {
+		t.Fatalf("expected '%s', got '%s'", id.String(), parsed.String())
+	}
+
+	if _, err := userconfig.ParseImageDefinition("myorg/foo@sha256:1234"); !userconfig.IsInvalidImageDefinition(err) {
+		t.Fatalf("expected error to be InvalidImageDefinitionError, got: %#v", err)
+	}
+}
+
+func TestImagePolicies(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+
+	tests := []struct {
+		Image  string
+		ValCtx userconfig.ValidationContext
+		Valid  bool
+	}{
+		{"myorg/foo", userconfig.ValidationContext{}, true},
+		{"myorg/foo", userconfig.ValidationContext{RequireImageTag: true}, false},
+		{"myorg/foo:1.0", userconfig.ValidationContext{RequireImageTag: true}, true},
+		{"myorg/foo", userconfig.ValidationContext{RejectLatestTag: true}, false},
+		{"myorg/foo:latest", userconfig.ValidationContext{RejectLatestTag: true}, false},
+		{"myorg/foo@" + digest, userconfig.ValidationContext{RejectLatestTag: true}, true},
+		{"myorg/foo:1.0", userconfig.ValidationContext{RejectLatestTag: true}, true},
+		{"myorg/foo:1.0", userconfig.ValidationContext{RequireImageDigest: true}, false},
+		{"myorg/foo:1.0@" + digest, userconfig.ValidationContext{RequireImageDigest: true}, true},
+		{"registry.giantswarm.io/myorg/foo", userconfig.ValidationContext{AllowedRegistries: []string{"*.giantswarm.io"}}, true},
+		{"myorg/foo", userconfig.ValidationContext{AllowedRegistries: []string{"*.giantswarm.io"}}, false},
+		{"myorg/foo", userconfig.ValidationContext{AllowedRegistries: []string{"docker.io"}}, true},
+		{"quay.io/myorg/foo", userconfig.ValidationContext{DeniedRegistries: []string{"quay.io"}}, false},
+		{"registry.giantswarm.io/myorg/foo", userconfig.ValidationContext{DeniedRegistries: []string{"quay.io"}}, true},
+	}
+
+	for i, test := range tests {
+		id := userconfig.MustParseImageDefinition(test.Image)
+		err := id.Validate(&test.ValCtx)
+		if test.Valid && err != nil {
+			t.Fatalf("test %d: expected image to be valid, got error: %#v", i, err)
+		}
+		if !test.Valid && !userconfig.IsInvalidImageDefinition(err) {
+			t.Fatalf("test %d: expected error to be InvalidImageDefinitionError, got: %#v", i, err)
+		}
+	}
+}
+
+func TestPinImageDigests(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	resolver := userconfig.InMemoryDigestResolver{
+		"registry.giantswarm.io/landingpage:0.10.0":  digest,
+		"registry.giantswarm.io/giantswarm/b:0.10.0": digest,
+	}
+
+	def := ExampleDefinition()
+	if err := def.PinImageDigests(resolver); err != nil {
+		t.Fatalf("pinning digests failed: %#v", err)
+	}
+	for name, c := range def.Components {
+		if c.Image != nil && c.Image.Digest != digest {
+			t.Fatalf("image of '%s' not pinned: %s", name, c.Image.String())
+		}
+	}
+
+	def = ExampleDefinition()
+	if err := def.PinImageDigests(userconfig.InMemoryDigestResolver{}); !userconfig.IsImageDigestNotFound(err) {
+		t.Fatalf("expected error to be ImageDigestNotFoundError, got: %#v", err)
+	}
+}
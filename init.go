@@ -0,0 +1,119 @@
+package userconfig
+
+import (
+	"sort"
+)
+
+// IsInit returns true if this component is an init step of its pod.
+func (nd *ComponentDefinition) IsInit() bool {
+	return nd.Init > 0
+}
+
+// validateInitInPod checks the init members of the pod specified by the
+// component with the given name. Init members run to completion before all
+// other members are started, so they cannot be reached by other components.
+func (nds ComponentDefinitions) validateInitInPod(name ComponentName, podComponents ComponentDefinitions) error {
+	order := map[int]ComponentName{}
+	hasMain := false
+	for childName, childDef := range podComponents {
+		if !childDef.IsInit() {
+			if childDef.IsComponent() {
+				hasMain = true
+			}
+			continue
+		}
+
+		if otherName, ok := order[childDef.Init]; ok {
+			return maskf(InvalidPodConfigError, "components '%s' and '%s' cannot both use init position '%d'", otherName.String(), childName.String(), childDef.Init)
+		}
+		order[childDef.Init] = childName
+
+		if !childDef.IsComponent() {
+			return maskf(InvalidPodConfigError, "init component '%s' must have an 'image'", childName.String())
+		}
+
+		// Checked before the ports, as links are only valid to exported ports
+		for linkingName, linkingDef := range nds {
+			for _, link := range linkingDef.Links {
+				if !link.LinksToOtherService() && link.Component == childName {
+					return maskf(InvalidPodConfigError, "component '%s' cannot link to init component '%s'", linkingName.String(), childName.String())
+				}
+			}
+		}
+		if len(childDef.Ports) > 0 {
+			return maskf(InvalidPodConfigError, "init component '%s' cannot export ports", childName.String())
+		}
+		if len(childDef.Expose) > 0 || len(childDef.Domains) > 0 {
+			return maskf(InvalidPodConfigError, "init component '%s' cannot expose ports or have domains", childName.String())
+		}
+		if childDef.IsJob() {
+			return maskf(InvalidPodConfigError, "init component '%s' cannot be a job", childName.String())
+		}
+		if childDef.Restart != nil && childDef.Restart.Policy == RestartAlways {
+			return maskf(InvalidPodConfigError, "init component '%s' cannot use restart policy '%s'", childName.String(), RestartAlways)
+		}
+	}
+
+	if len(order) > 0 && !hasMain {
+		return maskf(InvalidPodConfigError, "pod under '%s' must have at least one component that is not an init component", name.String())
+	}
+
+	return nil
+}
+
+// initValidationContext returns a copy of the given validation context, that
+// applies restart defaults suitable for init components. A default restart
+// policy of always becomes on-failure, since init components have to finish.
+func initValidationContext(valCtx *ValidationContext) *ValidationContext {
+	initValCtx := *valCtx
+	if initValCtx.RestartPolicy == RestartAlways {
+		initValCtx.RestartPolicy = RestartOnFailure
+	}
+	return &initValCtx
+}
+
+// validateInitComponents checks that only members of a pod are init components.
+func (nds ComponentDefinitions) validateInitComponents() error {
+	for componentName, componentDef := range nds {
+		if componentDef.Init < 0 {
+			return maskf(InvalidPodConfigError, "init position of component '%s' cannot be negative", componentName.String())
+		}
+		if componentDef.IsInit() && (componentDef.IsPodRoot() || !nds.IsPartOfPod(componentName)) {
+			return maskf(InvalidPodConfigError, "component '%s' must be part of a pod to be an init component", componentName.String())
+		}
+	}
+
+	return nil
+}
+
+// InitComponents returns the names of all init components of the pod
+// specified by a component with the given name, in the order they have to
+// run.
+func (nds *ComponentDefinitions) InitComponents(name ComponentName) (ComponentNames, error) {
+	podComponents, err := nds.PodComponents(name)
+	if err != nil {
+		return nil, mask(err)
+	}
+
+	names := ComponentNames{}
+	for childName, childDef := range podComponents {
+		if childDef.IsInit() {
+			names = append(names, childName)
+		}
+	}
+	sort.Sort(initOrder{names, podComponents})
+
+	return names, nil
+}
+
+// initOrder sorts component names by the init position of their components.
+type initOrder struct {
+	names ComponentNames
+	defs  ComponentDefinitions
+}
+
+func (io initOrder) Len() int      { return len(io.names) }
+func (io initOrder) Swap(i, j int) { io.names[i], io.names[j] = io.names[j], io.names[i] }
+func (io initOrder) Less(i, j int) bool {
+	return io.defs[io.names[i]].Init < io.defs[io.names[j]].Init
+}
@@ -0,0 +1,144 @@
+package userconfig_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/generic-types-go"
+	"github.com/giantswarm/user-config"
+)
+
+func testInitPod() userconfig.ServiceDefinition {
+	service := testService()
+	service.Components["pod"] = setPod(&userconfig.ComponentDefinition{}, userconfig.PodChildren)
+	service.Components["pod/app"] = testComponent()
+	service.Components["pod/app"].Ports = userconfig.PortDefinitions{generictypes.MustParseDockerPort("80/tcp")}
+	service.Components["pod/app"].Volumes = userconfig.VolumeDefinitions{{Path: "/data", Size: "1 GB"}}
+	service.Components["pod/fetch"] = testComponent()
+	service.Components["pod/fetch"].Init = 1
+	service.Components["pod/fetch"].Volumes = userconfig.VolumeDefinitions{{VolumeFrom: "pod/app", VolumePath: "/data"}}
+	service.Components["pod/prepare"] = testComponent()
+	service.Components["pod/prepare"].Init = 2
+	service.Components["pod/prepare"].Volumes = userconfig.VolumeDefinitions{{VolumesFrom: "pod/app"}}
+	return service
+}
+
+func TestInitComponents(t *testing.T) {
+	service := testInitPod()
+	if err := service.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+
+	names, err := service.Components.InitComponents("pod")
+	if err != nil {
+		t.Fatalf("InitComponents failed: %#v", err)
+	}
+	if len(names) != 2 || names[0] != "pod/fetch" || names[1] != "pod/prepare" {
+		t.Fatalf("invalid init components: %v", names)
+	}
+}
+
+func TestInitComponentsValidation(t *testing.T) {
+	tests := []func(service userconfig.ServiceDefinition){
+		// Same position
+		func(service userconfig.ServiceDefinition) {
+			service.Components["pod/prepare"].Init = 1
+		},
+		// Negative position
+		func(service userconfig.ServiceDefinition) {
+			service.Components["pod/prepare"].Init = -1
+		},
+		// Exports ports
+		func(service userconfig.ServiceDefinition) {
+			service.Components["pod/prepare"].Ports = userconfig.PortDefinitions{generictypes.MustParseDockerPort("81/tcp")}
+		},
+		// Is a job
+		func(service userconfig.ServiceDefinition) {
+			service.Components["pod/prepare"].Run = userconfig.RunOnce
+		},
+		// Not part of a pod
+		func(service userconfig.ServiceDefinition) {
+			service.Components["other"] = testComponent()
+			service.Components["other"].Init = 1
+		},
+		// Restarted always
+		func(service userconfig.ServiceDefinition) {
+			service.Components["pod/prepare"].Restart = &userconfig.RestartDefinition{Policy: userconfig.RestartAlways}
+		},
+		// Pod consists of init components only
+		func(service userconfig.ServiceDefinition) {
+			service.Components["pod/app"].Init = 3
+			service.Components["pod/app"].Ports = nil
+		},
+	}
+
+	for i, modify := range tests {
+		service := testInitPod()
+		modify(service)
+
+		if err := service.Validate(nil); !userconfig.IsInvalidPodConfig(err) {
+			t.Fatalf("test %d: expected error to be InvalidPodConfigError, got: %#v", i, err)
+		}
+	}
+}
+
+func TestInitRestartDefaults(t *testing.T) {
+	service := testInitPod()
+
+	valCtx := NewValidationContext()
+	valCtx.RestartPolicy = userconfig.RestartAlways
+	if err := service.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+
+	for _, name := range []userconfig.ComponentName{"pod/fetch", "pod/prepare"} {
+		if restart := service.Components[name].Restart; restart == nil || restart.Policy != userconfig.RestartOnFailure {
+			t.Fatalf("expected init component '%s' to default to restart policy 'on-failure', got: %s", name, restart.String())
+		}
+	}
+	if restart := service.Components["pod/app"].Restart; restart == nil || restart.Policy != userconfig.RestartAlways {
+		t.Fatalf("expected component to default to restart policy 'always', got: %s", restart.String())
+	}
+	if err := service.Validate(valCtx); err != nil {
+		t.Fatalf("validating service failed: %#v", err)
+	}
+
+	hidden, err := service.HideDefaults(valCtx)
+	if err != nil {
+		t.Fatalf("hiding defaults failed: %#v", err)
+	}
+	if hidden.Components["pod/fetch"].Restart != nil {
+		t.Fatalf("expected init restart defaults to be hidden, got: %s", hidden.Components["pod/fetch"].Restart.String())
+	}
+}
+
+func TestInitRestartPolicyInPod(t *testing.T) {
+	service := testInitPod()
+	service.Components["pod/app"].Restart = &userconfig.RestartDefinition{Policy: userconfig.RestartAlways}
+	service.Components["pod/fetch"].Restart = &userconfig.RestartDefinition{Policy: userconfig.RestartNever}
+	service.Components["pod/prepare"].Restart = &userconfig.RestartDefinition{Policy: userconfig.RestartOnFailure, MaxRetries: 3}
+
+	if err := service.Validate(nil); err != nil {
+		t.Fatalf("expected init components to use their own restart policy, got error: %#v", err)
+	}
+}
+
+func TestLinkToInitComponent(t *testing.T) {
+	service := testInitPod()
+	// Export the port, so the link itself is valid and the init rule is reached
+	service.Components["pod/fetch"].Ports = userconfig.PortDefinitions{generictypes.MustParseDockerPort("8080/tcp")}
+	service.Components["pod/app"].Links = userconfig.LinkDefinitions{
+		userconfig.LinkDefinition{
+			Component:  "pod/fetch",
+			TargetPort: generictypes.MustParseDockerPort("8080/tcp"),
+		},
+	}
+
+	err := service.Validate(nil)
+	if !userconfig.IsInvalidPodConfig(err) {
+		t.Fatalf("expected InvalidPodConfigError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "cannot link to init component") {
+		t.Fatalf("expected link to init component to be rejected, got %v", err)
+	}
+}
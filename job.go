@@ -0,0 +1,208 @@
+package userconfig
+
+import (
+	"encoding/json"
+)
+
+const (
+	RunOnce RunEnum = "once" // The component runs to completion once, e.g. a migration.
+)
+
+// Type of the "run" field in a component definition.
+type RunEnum string
+
+func (re RunEnum) String() string {
+	return string(re)
+}
+
+// UnmarshalJSON performs a validation during unmarshaling.
+func (re *RunEnum) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return mask(err)
+	}
+
+	rv := RunEnum(s)
+	if err := rv.Validate(); err != nil {
+		return mask(err)
+	}
+
+	*re = rv
+	return nil
+}
+
+// Validate checks that the given enum is a valid value.
+func (re RunEnum) Validate() error {
+	if re != RunOnce {
+		return maskf(InvalidJobDefinitionError, "invalid run value '%s'", string(re))
+	}
+	return nil
+}
+
+// IsJob returns true if this component runs to completion instead of being a
+// long-running service, i.e. if run or schedule is set.
+func (nd *ComponentDefinition) IsJob() bool {
+	return nd.Run != "" || nd.Schedule != ""
+}
+
+// validateJob checks that a job does not use any settings that only make
+// sense for long-running services.
+func (nd *ComponentDefinition) validateJob() error {
+	if nd.Run != "" {
+		if err := nd.Run.Validate(); err != nil {
+			return mask(diagnosticInField(err, "run"))
+		}
+	}
+
+	if nd.Schedule != "" {
+		if err := validateCronSchedule(nd.Schedule); err != nil {
+			return mask(diagnosticInField(err, "schedule"))
+		}
+	}
+
+	if !nd.IsJob() {
+		return nil
+	}
+
+	if nd.Run != "" && nd.Schedule != "" {
+		return newDiagnostic(InvalidJobDefinitionError, "run and schedule cannot be set both").withField("schedule").withFix("remove either run or schedule")
+	}
+
+	if len(nd.Domains) > 0 {
+		return newDiagnostic(InvalidJobDefinitionError, "jobs cannot have domains").withField("domains")
+	}
+
+	if len(nd.Expose) > 0 {
+		return newDiagnostic(InvalidJobDefinitionError, "jobs cannot expose ports").withField("expose")
+	}
+
+	if nd.Scale != nil && (nd.Scale.Min > 1 || nd.Scale.Max > 1) {
+		return newDiagnostic(InvalidJobDefinitionError, "jobs cannot be scaled above 1").withField("scale")
+	}
+
+	if nd.Scale != nil && nd.Scale.Autoscale != nil {
+		return newDiagnostic(InvalidJobDefinitionError, "jobs cannot be scaled automatically").withField("scale.autoscale")
+	}
+
+	if nd.Restart != nil && nd.Restart.Policy == RestartAlways {
+		return newDiagnostic(InvalidJobDefinitionError, "jobs cannot use restart policy '%s'", RestartAlways).withField("restart.policy").withValue(RestartAlways)
+	}
+
+	return nil
+}
+
+// jobValidationContext returns a copy of the given validation context, that
+// applies scaling and restart defaults suitable for jobs. A default restart
+// policy of always becomes on-failure, since jobs cannot use always.
+func jobValidationContext(valCtx *ValidationContext) *ValidationContext {
+	jobValCtx := *valCtx
+	jobValCtx.MinScaleSize = 1
+	jobValCtx.MaxScaleSize = 1
+	jobValCtx.Autoscale = nil
+	if jobValCtx.RestartPolicy == RestartAlways {
+		jobValCtx.RestartPolicy = RestartOnFailure
+	}
+	return &jobValCtx
+}
+
+// validateJobsInPods checks that the components of a pod are either all jobs
+// with the same run and schedule settings, or none of them is a job.
+func (nds *ComponentDefinitions) validateJobsInPods() error {
+	for componentName, componentDef := range *nds {
+		if !componentDef.IsPodRoot() {
+			continue
+		}
+
+		podComponents, err := nds.PodComponents(componentName)
+		if err != nil {
+			return mask(err)
+		}
+
+		var first *ComponentDefinition
+		for _, c := range podComponents {
+			if first == nil {
+				first = c
+				continue
+			}
+			if c.Run != first.Run || c.Schedule != first.Schedule {
+				return maskf(InvalidJobDefinitionError, "different run or schedule settings in pod under '%s'", componentName.String())
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateAfter checks that all components referenced by after exist, that
+// they can actually complete, and that the start order contains no cycles.
+func (nds ComponentDefinitions) validateAfter() error {
+	for componentName, component := range nds {
+		for _, name := range component.After {
+			if name == componentName {
+				return maskf(InvalidComponentDefinitionError, "component '%s' cannot be started after itself", componentName.String())
+			}
+
+			target, err := nds.ComponentByName(name)
+			if IsComponentNotFound(err) {
+				return maskf(InvalidComponentDefinitionError, "invalid after in component '%s': component '%s' does not exists", componentName.String(), name.String())
+			} else if err != nil {
+				return maskf(InvalidComponentDefinitionError, "unexpected error: %#v", err)
+			}
+
+			if target.Schedule != "" {
+				return maskf(InvalidComponentDefinitionError, "invalid after in component '%s': component '%s' is a scheduled job", componentName.String(), name.String())
+			}
+		}
+
+		if err := nds.detectStartOrderCycle(componentName); err != nil {
+			return mask(err)
+		}
+	}
+
+	return nil
+}
+
+// startDependencies returns the names of all components of this service that
+// must be started before the given component, either because they are linked
+// to, or because they are listed in after.
+func (nds ComponentDefinitions) startDependencies(name ComponentName) ComponentNames {
+	component, err := nds.ComponentByName(name)
+	if err != nil {
+		return nil
+	}
+
+	deps := ComponentNames{}
+	for _, link := range component.Links {
+		if link.LinksToOtherService() {
+			continue
+		}
+		deps = append(deps, link.Component)
+	}
+	deps = append(deps, component.After...)
+
+	return deps.Unique()
+}
+
+// detectStartOrderCycle follows links and after references, starting at the
+// given component, and returns an error in case it finds a loop.
+func (nds ComponentDefinitions) detectStartOrderCycle(name ComponentName) error {
+	visiting := map[ComponentName]bool{}
+
+	var recursive func(n ComponentName) error
+	recursive = func(n ComponentName) error {
+		if visiting[n] {
+			return maskf(InvalidComponentDefinitionError, "start order cycle detected at component '%s'", n.String())
+		}
+		visiting[n] = true
+		for _, dep := range nds.startDependencies(n) {
+			if err := recursive(dep); err != nil {
+				return err
+			}
+		}
+		visiting[n] = false
+		return nil
+	}
+
+	return recursive(name)
+}
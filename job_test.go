@@ -0,0 +1,260 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/giantswarm/generic-types-go"
+	"github.com/giantswarm/user-config"
+)
+
+func TestParseJobs(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"migrate": {
+				"image": "registry/namespace/migrate:version",
+				"run": "once"
+			},
+			"cleanup": {
+				"image": "registry/namespace/cleanup:version",
+				"schedule": "0 3 * * *"
+			},
+			"api": {
+				"image": "registry/namespace/api:version",
+				"ports": [ "8080/tcp" ],
+				"after": [ "migrate" ]
+			}
+		}
+	}`)
+
+	var serviceDef userconfig.ServiceDefinition
+	if err := json.Unmarshal(b, &serviceDef); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	if !serviceDef.Components["migrate"].IsJob() || serviceDef.Components["migrate"].Run != userconfig.RunOnce {
+		t.Fatalf("expected 'migrate' to be a job")
+	}
+	if !serviceDef.Components["cleanup"].IsJob() || serviceDef.Components["cleanup"].Schedule != "0 3 * * *" {
+		t.Fatalf("expected 'cleanup' to be a job")
+	}
+	if serviceDef.Components["api"].IsJob() {
+		t.Fatalf("expected 'api' not to be a job")
+	}
+
+	if err := serviceDef.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+
+	// Invalid run value
+	b = []byte(`{ "components": { "migrate": { "image": "registry/namespace/migrate:version", "run": "twice" } } }`)
+	if err := json.Unmarshal(b, &serviceDef); !userconfig.IsInvalidJobDefinition(err) {
+		t.Fatalf("expected error to be InvalidJobDefinitionError, got: %#v", err)
+	}
+}
+
+func TestJobValidation(t *testing.T) {
+	tests := []struct {
+		Modify func(c *userconfig.ComponentDefinition)
+		Valid  bool
+	}{
+		// Valid ones
+		{func(c *userconfig.ComponentDefinition) { c.Run = userconfig.RunOnce }, true},
+		{func(c *userconfig.ComponentDefinition) { c.Schedule = "*/15 * * * *" }, true},
+		{func(c *userconfig.ComponentDefinition) { c.Schedule = "0 3 * * 1-5" }, true},
+		{func(c *userconfig.ComponentDefinition) { c.Schedule = "0,30 8-18/2 1 1,6,12 7" }, true},
+		{func(c *userconfig.ComponentDefinition) { c.Schedule = "@daily" }, true},
+		{func(c *userconfig.ComponentDefinition) {
+			c.Run = userconfig.RunOnce
+			c.Restart = &userconfig.RestartDefinition{Policy: userconfig.RestartOnFailure, MaxRetries: 3}
+		}, true},
+
+		// Invalid ones
+		{func(c *userconfig.ComponentDefinition) { c.Schedule = "0 3 * *" }, false},
+		{func(c *userconfig.ComponentDefinition) { c.Schedule = "60 3 * * *" }, false},
+		{func(c *userconfig.ComponentDefinition) { c.Schedule = "0 3 0 * *" }, false},
+		{func(c *userconfig.ComponentDefinition) { c.Schedule = "0 5-3 * * *" }, false},
+		{func(c *userconfig.ComponentDefinition) { c.Schedule = "*/0 * * * *" }, false},
+		{func(c *userconfig.ComponentDefinition) { c.Schedule = "@sometimes" }, false},
+		{func(c *userconfig.ComponentDefinition) {
+			c.Run = userconfig.RunOnce
+			c.Schedule = "@daily"
+		}, false},
+		{func(c *userconfig.ComponentDefinition) {
+			c.Run = userconfig.RunOnce
+			c.Domains = userconfig.V2DomainDefinitions{"job.giantswarm.io": userconfig.PortDefinitions{generictypes.MustParseDockerPort("80/tcp")}}
+		}, false},
+		{func(c *userconfig.ComponentDefinition) {
+			c.Run = userconfig.RunOnce
+			c.Scale = &userconfig.ScaleDefinition{Min: 1, Max: 2}
+		}, false},
+		{func(c *userconfig.ComponentDefinition) {
+			c.Run = userconfig.RunOnce
+			c.Restart = &userconfig.RestartDefinition{Policy: userconfig.RestartAlways}
+		}, false},
+	}
+
+	for i, test := range tests {
+		def := ExampleDefinition()
+		test.Modify(def.Components["component/a"])
+
+		err := def.Validate(nil)
+		if test.Valid && err != nil {
+			t.Fatalf("test %d: expected definition to be valid, got error: %#v", i, err)
+		}
+		if !test.Valid && !userconfig.IsInvalidJobDefinition(err) {
+			t.Fatalf("test %d: expected error to be InvalidJobDefinitionError, got: %#v", i, err)
+		}
+	}
+}
+
+func TestJobScaleDefaults(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/a"].Run = userconfig.RunOnce
+
+	valCtx := NewValidationContext()
+	if err := def.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+
+	scale := def.Components["component/a"].Scale
+	if scale.Min != 1 || scale.Max != 1 {
+		t.Fatalf("expected job to be scaled to 1, got: %s", scale.String())
+	}
+	if err := def.Validate(valCtx); err != nil {
+		t.Fatalf("validating service failed: %#v", err)
+	}
+
+	hidden, err := def.HideDefaults(valCtx)
+	if err != nil {
+		t.Fatalf("hiding defaults failed: %#v", err)
+	}
+	if hidden.Components["component/a"].Scale != nil {
+		t.Fatalf("expected job scale defaults to be hidden, got: %s", hidden.Components["component/a"].Scale.String())
+	}
+}
+
+func TestLinkToJob(t *testing.T) {
+	def := testService()
+	def.Components["migrate"] = testComponent()
+	def.Components["migrate"].Run = userconfig.RunOnce
+	def.Components["migrate"].Ports = userconfig.PortDefinitions{generictypes.MustParseDockerPort("80/tcp")}
+	def.Components["api"] = testComponent()
+	def.Components["api"].Links = userconfig.LinkDefinitions{
+		userconfig.LinkDefinition{
+			Component:  "migrate",
+			TargetPort: generictypes.MustParseDockerPort("80/tcp"),
+		},
+	}
+
+	if err := def.Validate(nil); !userconfig.IsInvalidLinkDefinition(err) {
+		t.Fatalf("expected error to be InvalidLinkDefinitionError, got: %#v", err)
+	}
+}
+
+func TestAfterValidation(t *testing.T) {
+	def := testService()
+	def.Components["migrate"] = testComponent()
+	def.Components["migrate"].Run = userconfig.RunOnce
+	def.Components["api"] = testComponent()
+	def.Components["api"].After = userconfig.ComponentNames{"migrate"}
+
+	if err := def.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+
+	// Unknown component
+	def.Components["api"].After = userconfig.ComponentNames{"unknown"}
+	if err := def.Validate(nil); !userconfig.IsInvalidComponentDefinition(err) {
+		t.Fatalf("expected error to be InvalidComponentDefinitionError, got: %#v", err)
+	}
+
+	// Cycle
+	def.Components["api"].After = userconfig.ComponentNames{"migrate"}
+	def.Components["migrate"].After = userconfig.ComponentNames{"api"}
+	if err := def.Validate(nil); !userconfig.IsInvalidComponentDefinition(err) {
+		t.Fatalf("expected error to be InvalidComponentDefinitionError, got: %#v", err)
+	}
+
+	// Scheduled jobs never complete before
+	def.Components["migrate"].After = nil
+	def.Components["migrate"].Run = ""
+	def.Components["migrate"].Schedule = "@hourly"
+	if err := def.Validate(nil); !userconfig.IsInvalidComponentDefinition(err) {
+		t.Fatalf("expected error to be InvalidComponentDefinitionError, got: %#v", err)
+	}
+}
+
+func Test_AllDefsPerPod_Sorting_After(t *testing.T) {
+	def := testService()
+
+	// Component "api" must be started after the job "migrate" completed.
+	// Therefore we expect to get 2 maps from AllDefsPerPod, the first containing 'migrate', the second containing 'api'
+	def.Components["api"] = testComponent()
+	def.Components["api"].After = userconfig.ComponentNames{"migrate"}
+	def.Components["migrate"] = testComponent()
+	def.Components["migrate"].Run = userconfig.RunOnce
+
+	if err := def.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+
+	defs, err := def.Components.AllDefsPerPod(userconfig.ComponentNames{"api", "migrate"})
+	if err != nil {
+		t.Fatalf("AllDefsPerPod failed: %#v", err)
+	}
+	if len(defs) != 2 {
+		t.Fatalf("Expected to get 2 maps, got %d", len(defs))
+	}
+	if !defs[0].Contains("migrate") {
+		t.Fatalf("Expected 'migrate' to come first, got %#v", defs[0])
+	}
+	if !defs[1].Contains("api") {
+		t.Fatalf("Expected 'api' to come last, got %#v", defs[1])
+	}
+}
+
+func TestJobDiff(t *testing.T) {
+	oldDef := ExampleDefinition()
+	newDef := ExampleDefinition()
+	newDef.Components["component/a"].Schedule = "@daily"
+	newDef.Components["component/b"].After = userconfig.ComponentNames{"component/a"}
+
+	diffInfos := userconfig.ServiceDiff(oldDef, newDef)
+	if len(userconfig.DiffInfosByType(diffInfos, userconfig.DiffTypeComponentScheduleUpdated)) != 1 {
+		t.Fatalf("expected schedule diff: %#v", diffInfos)
+	}
+	if len(userconfig.DiffInfosByType(diffInfos, userconfig.DiffTypeComponentAfterUpdated)) != 1 {
+		t.Fatalf("expected after diff: %#v", diffInfos)
+	}
+}
+
+func TestJobRestartDefaults(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/a"].Run = userconfig.RunOnce
+
+	valCtx := NewValidationContext()
+	valCtx.RestartPolicy = userconfig.RestartAlways
+	if err := def.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+
+	restart := def.Components["component/a"].Restart
+	if restart == nil || restart.Policy != userconfig.RestartOnFailure {
+		t.Fatalf("expected job to default to restart policy 'on-failure', got: %s", restart.String())
+	}
+	if restart := def.Components["component/b"].Restart; restart == nil || restart.Policy != userconfig.RestartAlways {
+		t.Fatalf("expected component to default to restart policy 'always', got: %s", restart.String())
+	}
+	if err := def.Validate(valCtx); err != nil {
+		t.Fatalf("validating service failed: %#v", err)
+	}
+
+	hidden, err := def.HideDefaults(valCtx)
+	if err != nil {
+		t.Fatalf("hiding defaults failed: %#v", err)
+	}
+	if hidden.Components["component/a"].Restart != nil {
+		t.Fatalf("expected job restart defaults to be hidden, got: %s", hidden.Components["component/a"].Restart.String())
+	}
+}
@@ -0,0 +1,127 @@
+package userconfig
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/generic-types-go"
+)
+
+var linkEnvInvalidCharsRegExp = regexp.MustCompile("[^A-Z0-9_]")
+
+// LinkTarget describes what a link of a component points to.
+type LinkTarget struct {
+	// Service linked to, if the link points to another service.
+	Service ServiceName
+
+	// Component implementing the link, if the link points to a component of
+	// the same service.
+	Component ComponentName
+
+	// Port the link is implemented on. For links to other services this is
+	// the target port of the link. Empty if unknown.
+	Port generictypes.DockerPort
+}
+
+// LinkEnvironment describes what a component sees of its links inside its
+// container.
+type LinkEnvironment struct {
+	// Hostnames maps the hostname of each link to its target, e.g. "db" =>
+	// component "storage/postgres" on port 5432/tcp.
+	Hostnames map[string]LinkTarget
+
+	// Variables maps the environment variables set for the links to their
+	// values, e.g. "DB_PORT_5432_TCP_ADDR" => "db".
+	Variables map[string]string
+
+	// Collisions lists the ordered names of the variables in the env of the
+	// component, that are also set for a link.
+	Collisions []string
+}
+
+// LinkEnvironment computes the hostnames and environment variables the
+// component with the given name gets for its links. Links within the service
+// are resolved through expose definitions to the component and port
+// implementing them.
+func (nds ComponentDefinitions) LinkEnvironment(name ComponentName) (*LinkEnvironment, error) {
+	component, err := nds.ComponentByName(name)
+	if err != nil {
+		return nil, mask(err)
+	}
+
+	env := &LinkEnvironment{
+		Hostnames:  map[string]LinkTarget{},
+		Variables:  map[string]string{},
+		Collisions: []string{},
+	}
+	owners := map[string]string{}
+	for _, link := range component.Links {
+		linkName, err := link.LinkName()
+		if err != nil {
+			return nil, mask(err)
+		}
+
+		target := LinkTarget{
+			Service: link.Service,
+			Port:    link.TargetPort,
+		}
+		if link.LinksToSameService() {
+			target.Component, target.Port, err = link.Resolve(nds)
+			if err != nil {
+				return nil, mask(err)
+			}
+		}
+		env.Hostnames[linkName] = target
+
+		for key, value := range linkVariables(linkName, target.Port) {
+			if other, ok := owners[key]; ok {
+				return nil, maskf(InvalidLinkDefinitionError, "links '%s' and '%s' of component '%s' both set variable '%s'", other, linkName, name, key)
+			}
+			owners[key] = linkName
+			env.Variables[key] = value
+		}
+	}
+
+	for _, key := range component.Env.Keys() {
+		if _, ok := env.Variables[key]; ok {
+			env.Collisions = append(env.Collisions, key)
+		}
+	}
+	sort.Strings(env.Collisions)
+
+	return env, nil
+}
+
+// linkVariables returns the environment variables set for a link with the
+// given name to the given port, in the style of docker links, e.g.
+//
+//	DB_PORT=tcp://db:5432
+//	DB_PORT_5432_TCP=tcp://db:5432
+//	DB_PORT_5432_TCP_ADDR=db
+//	DB_PORT_5432_TCP_PORT=5432
+//	DB_PORT_5432_TCP_PROTO=tcp
+func linkVariables(linkName string, port generictypes.DockerPort) map[string]string {
+	if port.Empty() {
+		return nil
+	}
+
+	prefix := linkEnvPrefix(linkName)
+	url := fmt.Sprintf("%s://%s:%s", port.Protocol, linkName, port.Port)
+	portPrefix := fmt.Sprintf("%s_PORT_%s_%s", prefix, port.Port, strings.ToUpper(port.Protocol))
+
+	return map[string]string{
+		prefix + "_PORT":      url,
+		portPrefix:            url,
+		portPrefix + "_ADDR":  linkName,
+		portPrefix + "_PORT":  port.Port,
+		portPrefix + "_PROTO": port.Protocol,
+	}
+}
+
+// linkEnvPrefix returns the prefix of the environment variables of a link
+// with the given name, e.g. "my-db" => "MY_DB".
+func linkEnvPrefix(linkName string) string {
+	return linkEnvInvalidCharsRegExp.ReplaceAllString(strings.ToUpper(linkName), "_")
+}
@@ -0,0 +1,194 @@
+package userconfig
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// envReferenceRegExp matches references to other variables in env values,
+	// e.g. "$DB_PORT" or "${DB_PORT}".
+	envReferenceRegExp = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?`)
+
+	// linkVariableRegExp matches the variables set for a link, e.g.
+	// "DB_PORT_5432_TCP_ADDR". The bare "DB_PORT" is not matched, since names
+	// like "HTTP_PORT" are common for other purposes.
+	linkVariableRegExp = regexp.MustCompile(`^([A-Z0-9_]+?)_PORT_[0-9]+_(TCP|UDP)(_ADDR|_PORT|_PROTO)?$`)
+
+	// linkHostnameRegExp matches hostnames that can refer to a link. Names
+	// with dots refer to hosts outside the service.
+	linkHostnameRegExp = regexp.MustCompile(`^[a-z0-9]([a-z0-9_-]*[a-z0-9])?$`)
+
+	// hostKeySuffixes are suffixes of env keys whose value is a hostname,
+	// e.g. "DB_HOST=db:5432".
+	hostKeySuffixes = []string{"_HOST", "_HOSTNAME", "_ADDR", "_ADDRESS", "_SERVER"}
+)
+
+// linkEnvWarnings returns a warning for each env variable of a component that
+// refers to a link the component does not have.
+func (nds ComponentDefinitions) linkEnvWarnings() Warnings {
+	warnings := Warnings{}
+	for _, name := range orderedComponentKeys(nds) {
+		componentName := ComponentName(name)
+		envWarnings, _ := nds[componentName].linkEnvReferences(componentName)
+		warnings = append(warnings, envWarnings...)
+	}
+
+	return warnings
+}
+
+// unusedLinkWarnings returns a warning for each link of a component that is
+// not referenced in its env. Links are often used without the env, e.g. by a
+// configuration file, so this is not part of linkEnvWarnings.
+func (nds ComponentDefinitions) unusedLinkWarnings() Warnings {
+	warnings := Warnings{}
+	for _, name := range orderedComponentKeys(nds) {
+		componentName := ComponentName(name)
+		_, unused := nds[componentName].linkEnvReferences(componentName)
+		for _, linkName := range unused {
+			warnings = append(warnings, Warning{
+				Component: componentName,
+				Message:   fmt.Sprintf("link '%s' is not referenced in env", linkName),
+			})
+		}
+	}
+
+	return warnings
+}
+
+// linkEnvReferences returns a warning for each env variable of this component
+// that refers to a link it does not have, and the names of the links that are
+// not referenced in the env.
+func (nd *ComponentDefinition) linkEnvReferences(componentName ComponentName) (Warnings, []string) {
+	// Collect links by hostname and variable prefix
+	linkNames := []string{}
+	byHostname := map[string]string{}
+	byPrefix := map[string]string{}
+	for _, link := range nd.Links {
+		linkName, err := link.LinkName()
+		if err != nil {
+			continue
+		}
+		linkNames = append(linkNames, linkName)
+		byHostname[strings.ToLower(linkName)] = linkName
+		byPrefix[linkEnvPrefix(linkName)] = linkName
+	}
+	hostnames, prefixes := []string{}, []string{}
+	for hostname := range byHostname {
+		hostnames = append(hostnames, hostname)
+	}
+	for prefix := range byPrefix {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(hostnames)
+	sort.Strings(prefixes)
+
+	keys := map[string]bool{}
+	for _, key := range nd.Env.Keys() {
+		keys[key] = true
+	}
+
+	warnings := Warnings{}
+	referenced := map[string]bool{}
+	for _, entry := range nd.Env {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := parts[0], parts[1]
+
+		// References to link variables
+		for _, match := range envReferenceRegExp.FindAllStringSubmatch(value, -1) {
+			variable := match[1]
+			if keys[variable] {
+				continue
+			}
+			if linkName, ok := byPrefix[strings.TrimSuffix(variable, "_PORT")]; ok {
+				referenced[linkName] = true
+				continue
+			}
+			if m := linkVariableRegExp.FindStringSubmatch(variable); m != nil {
+				if linkName, ok := byPrefix[m[1]]; ok {
+					referenced[linkName] = true
+					continue
+				}
+				message := fmt.Sprintf("env '%s' refers to variable '%s' of an undefined link, links are %v", key, variable, linkNames)
+				if prefix, ok := suggest(m[1], prefixes); ok {
+					message += fmt.Sprintf(", did you mean link '%s'?", byPrefix[prefix])
+				}
+				warnings = append(warnings, Warning{
+					Component: componentName,
+					Message:   message,
+				})
+			}
+		}
+
+		// References to link hostnames
+		if host, ok := envHostname(key, value); ok {
+			if linkName, ok := byHostname[host]; ok {
+				referenced[linkName] = true
+				continue
+			}
+			message := fmt.Sprintf("env '%s' refers to host '%s', which is not a link, links are %v", key, host, linkNames)
+			if hostname, ok := suggest(host, hostnames); ok {
+				message += fmt.Sprintf(", did you mean '%s'?", hostname)
+			}
+			warnings = append(warnings, Warning{
+				Component: componentName,
+				Message:   message,
+			})
+		}
+	}
+
+	unused := []string{}
+	for _, linkName := range linkNames {
+		if !referenced[linkName] {
+			unused = append(unused, linkName)
+		}
+	}
+
+	return warnings, unused
+}
+
+// envHostname returns the hostname the given env variable refers to, if it
+// looks like the name of a link. Hostnames are taken from URLs, e.g.
+// "postgres://db:5432/app", and from values of keys like "DB_HOST".
+func envHostname(key, value string) (string, bool) {
+	if strings.Contains(value, "$") {
+		// Refers to other variables, see link variables
+		return "", false
+	}
+
+	host := ""
+	if strings.Contains(value, "://") {
+		u, err := url.Parse(value)
+		if err != nil {
+			return "", false
+		}
+		host = u.Host
+	} else {
+		for _, suffix := range hostKeySuffixes {
+			if strings.HasSuffix(strings.ToUpper(key), suffix) {
+				host = value
+				break
+			}
+		}
+	}
+
+	// Strip the port, if any
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	host = strings.ToLower(host)
+
+	if host == "localhost" || !linkHostnameRegExp.MatchString(host) {
+		return "", false
+	}
+	return host, true
+}
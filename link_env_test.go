@@ -0,0 +1,104 @@
+package userconfig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/generic-types-go"
+	"github.com/giantswarm/user-config"
+)
+
+func linkEnvDefinition() userconfig.ServiceDefinition {
+	return userconfig.ServiceDefinition{
+		Components: userconfig.ComponentDefinitions{
+			"web": &userconfig.ComponentDefinition{
+				Image: userconfig.MustParseImageDefinition("busybox"),
+				Env:   userconfig.EnvList{"DB_PORT=5432", "MODE=production"},
+				Links: userconfig.LinkDefinitions{
+					{Component: "storage", Alias: "db", TargetPort: generictypes.MustParseDockerPort("5432/tcp")},
+					{Component: "lb", TargetPort: generictypes.MustParseDockerPort("8000/udp")},
+					{Service: "mail", TargetPort: generictypes.MustParseDockerPort("25/tcp")},
+				},
+			},
+			"storage": &userconfig.ComponentDefinition{
+				Image: userconfig.MustParseImageDefinition("postgres"),
+				Ports: userconfig.PortDefinitions{generictypes.MustParseDockerPort("5432/tcp")},
+			},
+			"lb": &userconfig.ComponentDefinition{
+				Expose: userconfig.ExposeDefinitions{
+					{Port: generictypes.MustParseDockerPort("8000/udp"), Component: "lb/app", TargetPort: generictypes.MustParseDockerPort("3000/udp")},
+				},
+			},
+			"lb/app": &userconfig.ComponentDefinition{
+				Image: userconfig.MustParseImageDefinition("busybox"),
+				Ports: userconfig.PortDefinitions{generictypes.MustParseDockerPort("3000/udp")},
+			},
+		},
+	}
+}
+
+func TestLinkEnvironment(t *testing.T) {
+	def := linkEnvDefinition()
+	env, err := def.Components.LinkEnvironment("web")
+	if err != nil {
+		t.Fatalf("LinkEnvironment failed: %v", err)
+	}
+
+	expectedHostnames := map[string]userconfig.LinkTarget{
+		"db":   {Component: "storage", Port: generictypes.MustParseDockerPort("5432/tcp")},
+		"lb":   {Component: "lb/app", Port: generictypes.MustParseDockerPort("3000/udp")},
+		"mail": {Service: "mail", Port: generictypes.MustParseDockerPort("25/tcp")},
+	}
+	if !reflect.DeepEqual(env.Hostnames, expectedHostnames) {
+		t.Fatalf("expected hostnames %v, got %v", expectedHostnames, env.Hostnames)
+	}
+
+	expectedVariables := map[string]string{
+		"DB_PORT":                "tcp://db:5432",
+		"DB_PORT_5432_TCP":       "tcp://db:5432",
+		"DB_PORT_5432_TCP_ADDR":  "db",
+		"DB_PORT_5432_TCP_PORT":  "5432",
+		"DB_PORT_5432_TCP_PROTO": "tcp",
+		"LB_PORT":                "udp://lb:3000",
+		"LB_PORT_3000_UDP":       "udp://lb:3000",
+		"LB_PORT_3000_UDP_ADDR":  "lb",
+		"LB_PORT_3000_UDP_PORT":  "3000",
+		"LB_PORT_3000_UDP_PROTO": "udp",
+		"MAIL_PORT":              "tcp://mail:25",
+		"MAIL_PORT_25_TCP":       "tcp://mail:25",
+		"MAIL_PORT_25_TCP_ADDR":  "mail",
+		"MAIL_PORT_25_TCP_PORT":  "25",
+		"MAIL_PORT_25_TCP_PROTO": "tcp",
+	}
+	if !reflect.DeepEqual(env.Variables, expectedVariables) {
+		t.Fatalf("expected variables %v, got %v", expectedVariables, env.Variables)
+	}
+
+	if !reflect.DeepEqual(env.Collisions, []string{"DB_PORT"}) {
+		t.Fatalf("expected collision of DB_PORT, got %v", env.Collisions)
+	}
+}
+
+func TestLinkEnvironmentErrors(t *testing.T) {
+	def := linkEnvDefinition()
+	if _, err := def.Components.LinkEnvironment("unknown"); !userconfig.IsComponentNotFound(err) {
+		t.Fatalf("expected ComponentNotFoundError, got %v", err)
+	}
+
+	// Aliases that result in the same variables
+	def.Components["web"].Links = userconfig.LinkDefinitions{
+		{Component: "storage", Alias: "my-db", TargetPort: generictypes.MustParseDockerPort("5432/tcp")},
+		{Component: "storage", Alias: "my_db", TargetPort: generictypes.MustParseDockerPort("5432/tcp")},
+	}
+	if _, err := def.Components.LinkEnvironment("web"); !userconfig.IsInvalidLinkDefinition(err) {
+		t.Fatalf("expected InvalidLinkDefinitionError, got %v", err)
+	}
+
+	// Unresolvable link
+	def.Components["web"].Links = userconfig.LinkDefinitions{
+		{Component: "storage", TargetPort: generictypes.MustParseDockerPort("1234/tcp")},
+	}
+	if _, err := def.Components.LinkEnvironment("web"); err == nil {
+		t.Fatalf("expected error for unresolvable link")
+	}
+}
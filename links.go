@@ -20,10 +20,58 @@ type LinkDefinition struct {
 
 	// Port of the required component
 	TargetPort generictypes.DockerPort `json:"target_port" description:"Port on the component that is linked to"`
+
+	// Name of the port of the required component, if it is referred to by
+	// name. Given as target_port, e.g. "http".
+	TargetPortName PortName `json:"-"`
 }
 
 type LinkDefinitions []LinkDefinition
 
+type linkDefinitionCopy LinkDefinition
+
+// UnmarshalJSON performs custom unmarshalling to support referring to the
+// target port by name.
+func (ld *LinkDefinition) UnmarshalJSON(data []byte) error {
+	var local struct {
+		linkDefinitionCopy
+		TargetPort json.RawMessage `json:"target_port"`
+	}
+	if err := decodeStrict(data, &local); err != nil {
+		return mask(err)
+	}
+
+	*ld = LinkDefinition(local.linkDefinitionCopy)
+	if len(local.TargetPort) > 0 && string(local.TargetPort) != "null" {
+		port, name, err := parsePortOrName(local.TargetPort)
+		if err != nil {
+			return mask(err)
+		}
+		ld.TargetPort = port
+		ld.TargetPortName = name
+	}
+
+	return nil
+}
+
+// MarshalJSON performs custom marshalling to keep the target port name, if
+// any.
+func (ld LinkDefinition) MarshalJSON() ([]byte, error) {
+	local := struct {
+		linkDefinitionCopy
+		TargetPort interface{} `json:"target_port"`
+	}{
+		linkDefinitionCopy: linkDefinitionCopy(ld),
+		TargetPort:         portOrName(ld.TargetPort, ld.TargetPortName),
+	}
+
+	data, err := json.Marshal(local)
+	if err != nil {
+		return nil, mask(err)
+	}
+	return data, nil
+}
+
 // String returns the string represantion of the current incarnation.
 func (ld LinkDefinition) String() string {
 	// A string map is reliable enough for our case, as the JSON implementation
@@ -35,6 +83,9 @@ func (ld LinkDefinition) String() string {
 		"alias":       ld.Alias,
 		"target_port": ld.TargetPort.String(),
 	}
+	if !ld.TargetPortName.Empty() {
+		m["target_port_name"] = ld.TargetPortName.String()
+	}
 
 	raw, err := json.Marshal(m)
 	if err != nil {
@@ -46,27 +97,37 @@ func (ld LinkDefinition) String() string {
 
 func (ld LinkDefinition) Validate(valCtx *ValidationContext) error {
 	if ld.Component.Empty() && ld.Service.Empty() {
-		return maskf(InvalidLinkDefinitionError, "link component must not be empty")
+		return newDiagnostic(InvalidLinkDefinitionError, "link component must not be empty").withField("component").withFix("set component or service")
 	}
 	if !ld.Component.Empty() {
 		if err := ld.Component.Validate(); err != nil {
-			return maskf(InvalidLinkDefinitionError, "invalid link component: %s", err.Error())
+			return newDiagnostic(InvalidLinkDefinitionError, "invalid link component: %s", err.Error()).withField("component").withValue(ld.Component).withUnderlying(err)
 		}
 	}
 	if !ld.Service.Empty() {
 		if err := ld.Service.Validate(); err != nil {
-			return maskf(InvalidLinkDefinitionError, "invalid link service: %s", err.Error())
+			return newDiagnostic(InvalidLinkDefinitionError, "invalid link service: %s", err.Error()).withField("service").withValue(ld.Service).withUnderlying(err)
 		}
 	}
 	if !ld.Component.Empty() && !ld.Service.Empty() {
-		return maskf(InvalidLinkDefinitionError, "link service and component cannot be set both")
+		return newDiagnostic(InvalidLinkDefinitionError, "link service and component cannot be set both").withField("service").withValue(ld.Service)
+	}
+
+	if !ld.TargetPortName.Empty() {
+		if err := ld.TargetPortName.Validate(); err != nil {
+			return newDiagnostic(InvalidLinkDefinitionError, "invalid link: %s", err.Error()).withField("target_port").withValue(ld.TargetPortName).withUnderlying(err)
+		}
+		if ld.TargetPort.Empty() {
+			// Not resolved, e.g. a link to another service
+			return nil
+		}
 	}
 
 	// for easy validation we create a port definitions type and use its
 	// validate method
 	pds := PortDefinitions{ld.TargetPort}
 	if err := pds.Validate(valCtx); err != nil {
-		return maskf(InvalidLinkDefinitionError, "invalid link: %s", err.Error())
+		return newDiagnostic(InvalidLinkDefinitionError, "invalid link: %s", err.Error()).withField("target_port").withValue(ld.TargetPort).withUnderlying(err)
 	}
 
 	return nil
@@ -89,7 +150,7 @@ func (ld LinkDefinition) LinkName() (string, error) {
 	if !ld.Service.Empty() {
 		return ld.Service.String(), nil
 	}
-	return "", mask(InvalidLinkDefinitionError)
+	return "", newDiagnostic(InvalidLinkDefinitionError, "").withFix("set an alias, component or service")
 }
 
 // LinksToOtherService returns true if this definition defines
@@ -107,18 +168,19 @@ func (ld LinkDefinition) LinksToSameService() bool {
 func (lds LinkDefinitions) Validate(valCtx *ValidationContext) error {
 	links := map[string]string{}
 
-	for _, link := range lds {
+	for i, link := range lds {
+		field := fmt.Sprintf("[%d]", i)
 		if err := link.Validate(valCtx); err != nil {
-			return mask(err)
+			return mask(diagnosticInField(err, field))
 		}
 
 		// detect duplicated link name
 		linkName, err := link.LinkName()
 		if err != nil {
-			return mask(err)
+			return mask(diagnosticInField(err, field))
 		}
 		if _, ok := links[linkName]; ok {
-			return maskf(InvalidLinkDefinitionError, "duplicate link: %s", linkName)
+			return newDiagnostic(InvalidLinkDefinitionError, "duplicate link: %s", linkName).withField(field).withValue(linkName).withFix("set a different alias")
 		}
 		links[linkName] = link.TargetPort.String()
 	}
@@ -157,54 +219,80 @@ func (link LinkDefinition) Resolve(nds ComponentDefinitions) (ComponentName, gen
 		return "", generictypes.DockerPort{}, maskf(ComponentNotFoundError, link.Component.String())
 	}
 
+	// Resolve the target port, if it is referred to by name
+	targetPort := link.TargetPort
+	if !link.TargetPortName.Empty() {
+		if targetPort, err = targetComponent.portByName(link.TargetPortName); err != nil {
+			return "", generictypes.DockerPort{}, maskf(InvalidLinkDefinitionError, "port name %s not found in %s", link.TargetPortName, targetName)
+		}
+	}
+
 	// If the linked to port exposed by the target component?
-	if expDef, err := targetComponent.Expose.defByPort(link.TargetPort); err == nil {
+	if expDef, err := targetComponent.Expose.defByPort(targetPort); err == nil {
 		// Link to exposed port, let expose definition resolve this further
 		return expDef.Resolve(targetName, nds)
 	}
 
-	if targetComponent.Ports.contains(link.TargetPort) {
+	if targetComponent.Ports.contains(targetPort) {
 		// Link points directly to an exported port of the target
-		return targetName, link.TargetPort, nil
+		return targetName, targetPort, nil
 	}
 
 	// Invalid link
-	return "", generictypes.DockerPort{}, maskf(InvalidLinkDefinitionError, "port %s not found in %s", link.TargetPort, targetName)
+	return "", generictypes.DockerPort{}, maskf(InvalidLinkDefinitionError, "port %s not found in %s", targetPort, targetName)
 }
 
 // validateLinks
 func (nds ComponentDefinitions) validateLinks() error {
 	for componentName, component := range nds {
 		// detect invalid links
-		for _, link := range component.Links {
+		for i, link := range component.Links {
 			// If the link is inter-service, we cannot validate it here.
 			if link.LinksToOtherService() {
 				continue
 			}
 
-			// Try to find the target component
-			targetName := ComponentName(link.Component)
-			targetComponent, err := nds.ComponentByName(targetName)
-			if IsComponentNotFound(err) {
-				return maskf(InvalidComponentDefinitionError, "invalid link to component '%s': does not exists", link.Component)
-			} else if err != nil {
-				return maskf(InvalidComponentDefinitionError, "unexpected error: %#v", err)
+			if err := nds.validateLink(componentName, link); err != nil {
+				return mask(diagnosticInField(diagnosticInComponent(err, componentName), fmt.Sprintf("links[%d]", i)))
 			}
+		}
+	}
 
-			// Does the target component expose the linked to port?
-			if !targetComponent.Expose.contains(link.TargetPort) && !targetComponent.Ports.contains(link.TargetPort) {
-				return maskf(InvalidComponentDefinitionError, "invalid link to component '%s': does not export port '%s'", link.Component, link.TargetPort)
-			}
+	return nil
+}
 
-			// Is the component allowed to link to the target component?
-			if !isLinkAllowed(componentName, targetName) {
-				return maskf(InvalidLinkDefinitionError, "invalid link to component '%s': component '%s' is not allowed to link to it", link.Component, componentName)
-			}
+// validateLink checks the given link of the given component against the
+// component it links to.
+func (nds ComponentDefinitions) validateLink(componentName ComponentName, link LinkDefinition) error {
+	// Try to find the target component
+	targetName := ComponentName(link.Component)
+	targetComponent, err := nds.ComponentByName(targetName)
+	if IsComponentNotFound(err) {
+		return newDiagnostic(InvalidComponentDefinitionError, "invalid link to component '%s': does not exists", link.Component).withValue(link.Component).withSuggestion(link.Component.String(), orderedComponentKeys(nds))
+	} else if err != nil {
+		return maskf(InvalidComponentDefinitionError, "unexpected error: %#v", err)
+	}
 
-			if err := nds.detectLinkCycle(link); err != nil {
-				return maskf(InvalidComponentDefinitionError, "invalid link to component '%s': %s", link.Component, err.Error())
-			}
+	// Does the target component expose the linked to port?
+	if !targetComponent.Expose.contains(link.TargetPort) && !targetComponent.Ports.contains(link.TargetPort) {
+		if other, ok := targetComponent.Ports.protocolMismatch(link.TargetPort); ok {
+			return newDiagnostic(InvalidComponentDefinitionError, "invalid link to component '%s': port '%s' does not match protocol of exported port '%s'", link.Component, link.TargetPort, other).withValue(link.TargetPort).withFix("link to port '%s'", other)
 		}
+		return newDiagnostic(InvalidComponentDefinitionError, "invalid link to component '%s': does not export port '%s'", link.Component, link.TargetPort).withValue(link.TargetPort).withFix("export port '%s' in component '%s'", link.TargetPort, link.Component).withSuggestion(link.TargetPort.String(), portCandidates(targetComponent.Ports, targetComponent.Expose))
+	}
+
+	// Jobs do not run permanently, so they cannot be linked to
+	if targetComponent.IsJob() {
+		return newDiagnostic(InvalidLinkDefinitionError, "invalid link to component '%s': component is a job", link.Component).withValue(link.Component)
+	}
+
+	// Is the component allowed to link to the target component?
+	if !isLinkAllowed(componentName, targetName) {
+		return newDiagnostic(InvalidLinkDefinitionError, "invalid link to component '%s': component '%s' is not allowed to link to it", link.Component, componentName).withValue(link.Component)
+	}
+
+	if err := nds.detectLinkCycle(link); err != nil {
+		return newDiagnostic(InvalidComponentDefinitionError, "invalid link to component '%s': %s", link.Component, err.Error()).withValue(link.Component)
 	}
 
 	return nil
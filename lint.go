@@ -0,0 +1,191 @@
+package userconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LintSeverity describes how serious a lint issue is.
+type LintSeverity string
+
+const (
+	// LintSeverityError marks issues that will most likely break the service.
+	LintSeverityError LintSeverity = "error"
+
+	// LintSeverityWarning marks issues that are likely not what the user
+	// intended.
+	LintSeverityWarning LintSeverity = "warning"
+
+	// LintSeverityInfo marks issues that are worth a look, but often fine.
+	LintSeverityInfo LintSeverity = "info"
+)
+
+const (
+	// lintIgnoreAll switches off all lint rules of a component, when given
+	// in x-lint-ignore.
+	lintIgnoreAll = "all"
+
+	// lintIgnoreDirective starts a line of a component comment that switches
+	// off lint rules, e.g. "lint-ignore: missing-memory-limit, unused-port".
+	lintIgnoreDirective = "lint-ignore:"
+)
+
+// LintRule describes a check of the lint subsystem.
+type LintRule struct {
+	// ID of the rule, used to switch it off, e.g. "missing-memory-limit".
+	ID string
+
+	Severity LintSeverity
+
+	// Human readable description of what the rule checks.
+	Description string
+
+	check func(nds ComponentDefinitions) Warnings
+}
+
+// LintRules returns all rules checked by ServiceDefinition.Lint.
+func LintRules() []LintRule {
+	return append([]LintRule{}, lintRules...)
+}
+
+func lintRuleByID(id string) (LintRule, bool) {
+	for _, rule := range lintRules {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return LintRule{}, false
+}
+
+// LintIssue describes a finding of a lint rule.
+type LintIssue struct {
+	// ID of the rule that reported the issue.
+	Rule string
+
+	Severity LintSeverity
+
+	// Component the issue is about.
+	Component ComponentName
+
+	// Human readable description of the issue.
+	Message string
+}
+
+func (li LintIssue) String() string {
+	return fmt.Sprintf("%s: component '%s': %s [%s]", li.Severity, li.Component, li.Message, li.Rule)
+}
+
+type LintIssues []LintIssue
+
+// BySeverity returns a copied list of lint issues, only containing the given
+// severity.
+func (lis LintIssues) BySeverity(severity LintSeverity) LintIssues {
+	newIssues := LintIssues{}
+
+	for _, li := range lis {
+		if li.Severity == severity {
+			newIssues = append(newIssues, li)
+		}
+	}
+
+	return newIssues
+}
+
+// HasErrors returns true if any issue has LintSeverityError.
+func (lis LintIssues) HasErrors() bool {
+	return len(lis.BySeverity(LintSeverityError)) > 0
+}
+
+// Lint runs all lint rules over this ServiceDefinition and returns the issues
+// found, ordered by component. Rules switched off by a component through
+// x-lint-ignore or its comment are not reported for that component.
+func (sd *ServiceDefinition) Lint() LintIssues {
+	issues := LintIssues{}
+	for _, rule := range lintRules {
+		for _, w := range sd.Components.notLintIgnored(rule.ID, rule.check(sd.Components)) {
+			issues = append(issues, LintIssue{
+				Rule:      rule.ID,
+				Severity:  rule.Severity,
+				Component: w.Component,
+				Message:   w.Message,
+			})
+		}
+	}
+	sort.Stable(lintIssuesByComponent(issues))
+
+	return issues
+}
+
+type lintIssuesByComponent LintIssues
+
+func (l lintIssuesByComponent) Len() int           { return len(l) }
+func (l lintIssuesByComponent) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l lintIssuesByComponent) Less(i, j int) bool { return l[i].Component < l[j].Component }
+
+// notLintIgnored returns the given warnings of the lint rule with the given
+// ID, except those of components that switch off the rule.
+func (nds ComponentDefinitions) notLintIgnored(ruleID string, warnings Warnings) Warnings {
+	result := Warnings{}
+	for _, w := range warnings {
+		if nd, ok := nds[w.Component]; ok && nd.lintIgnores(ruleID) {
+			continue
+		}
+		result = append(result, w)
+	}
+	return result
+}
+
+// lintIgnored returns the sorted IDs of the lint rules switched off for this
+// component, through x-lint-ignore and through the comment.
+func (nd *ComponentDefinition) lintIgnored() []string {
+	set := map[string]struct{}{}
+	for _, id := range nd.LintIgnore {
+		set[strings.TrimSpace(id)] = struct{}{}
+	}
+	for _, line := range strings.Split(nd.Comment, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, lintIgnoreDirective) {
+			continue
+		}
+		for _, id := range strings.Split(strings.TrimPrefix(line, lintIgnoreDirective), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				set[id] = struct{}{}
+			}
+		}
+	}
+
+	ids := []string{}
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// lintIgnores returns true if the lint rule with the given ID is switched off
+// for this component.
+func (nd *ComponentDefinition) lintIgnores(ruleID string) bool {
+	for _, id := range nd.lintIgnored() {
+		if id == ruleID || id == lintIgnoreAll {
+			return true
+		}
+	}
+	return false
+}
+
+// lintUnknownIgnores reports lint rules switched off by the component that
+// do not exist, so typos do not go unnoticed.
+func lintUnknownIgnores(nd *ComponentDefinition) []string {
+	messages := []string{}
+	for _, id := range nd.lintIgnored() {
+		if id == lintIgnoreAll {
+			continue
+		}
+		if _, ok := lintRuleByID(id); !ok {
+			messages = append(messages, fmt.Sprintf("cannot ignore unknown lint rule '%s'", id))
+		}
+	}
+	return messages
+}
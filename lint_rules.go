@@ -0,0 +1,206 @@
+package userconfig
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/generic-types-go"
+)
+
+// IDs of the lint rules, as reported by LintIssue.
+const (
+	LintRuleUnpinnedImage          = "unpinned-image"
+	LintRuleMissingMemoryLimit     = "missing-memory-limit"
+	LintRuleSingleInstanceStateful = "single-instance-stateful"
+	LintRuleUnusedPort             = "unused-port"
+	LintRuleVolumeWithoutSize      = "volume-without-size"
+	LintRuleRouteOverlap           = "route-overlap"
+	LintRuleLinkEnv                = "link-env"
+	LintRuleUnusedLink             = "unused-link"
+	LintRuleUnknownLintIgnore      = "unknown-lint-ignore"
+)
+
+var lintRules = []LintRule{
+	{
+		ID:          LintRuleUnpinnedImage,
+		Severity:    LintSeverityWarning,
+		Description: "The image has no tag, or uses 'latest', and no digest, so it can change between deployments.",
+		check:       perComponent(lintUnpinnedImage),
+	},
+	{
+		ID:          LintRuleMissingMemoryLimit,
+		Severity:    LintSeverityWarning,
+		Description: "The component has no memory-limit, so the server decides on a default.",
+		check:       perComponent(lintMissingMemoryLimit),
+	},
+	{
+		ID:          LintRuleSingleInstanceStateful,
+		Severity:    LintSeverityInfo,
+		Description: "The component has own volumes, but runs a single instance only, so it is a single point of failure.",
+		check:       perComponent(lintSingleInstanceStateful),
+	},
+	{
+		ID:          LintRuleUnusedPort,
+		Severity:    LintSeverityInfo,
+		Description: "An exported port is not linked to, exposed, bound to a domain or health checked.",
+		check:       lintUnusedPorts,
+	},
+	{
+		ID:          LintRuleVolumeWithoutSize,
+		Severity:    LintSeverityError,
+		Description: "A volume has a path, but no size.",
+		check:       perComponent(lintVolumeWithoutSize),
+	},
+	{
+		ID:          LintRuleRouteOverlap,
+		Severity:    LintSeverityWarning,
+		Description: "Path prefixes of a domain routed to different components are nested.",
+		check:       ComponentDefinitions.routeWarnings,
+	},
+	{
+		ID:          LintRuleLinkEnv,
+		Severity:    LintSeverityWarning,
+		Description: "The env refers to links the component does not have.",
+		check:       ComponentDefinitions.linkEnvWarnings,
+	},
+	{
+		ID:          LintRuleUnusedLink,
+		Severity:    LintSeverityInfo,
+		Description: "A link is not referenced in the env of the component, it may be used by other means though.",
+		check:       ComponentDefinitions.unusedLinkWarnings,
+	},
+}
+
+func init() {
+	// Added here, as its check looks up the other rules.
+	lintRules = append(lintRules, LintRule{
+		ID:          LintRuleUnknownLintIgnore,
+		Severity:    LintSeverityWarning,
+		Description: "A lint rule switched off through x-lint-ignore or the comment does not exist.",
+		check:       perComponent(lintUnknownIgnores),
+	})
+}
+
+// perComponent turns a check of a single component into a check of all
+// components, run in order of their names.
+func perComponent(check func(nd *ComponentDefinition) []string) func(nds ComponentDefinitions) Warnings {
+	return func(nds ComponentDefinitions) Warnings {
+		warnings := Warnings{}
+		for _, name := range orderedComponentKeys(nds) {
+			componentName := ComponentName(name)
+			for _, message := range check(nds[componentName]) {
+				warnings = append(warnings, Warning{Component: componentName, Message: message})
+			}
+		}
+		return warnings
+	}
+}
+
+func lintUnpinnedImage(nd *ComponentDefinition) []string {
+	if nd.Image == nil || nd.Image.Digest != "" {
+		return nil
+	}
+	if nd.Image.Version == "" || nd.Image.Version == latestTag {
+		return []string{fmt.Sprintf("image '%s' is not pinned to a tag or digest", nd.Image)}
+	}
+	return nil
+}
+
+func lintMissingMemoryLimit(nd *ComponentDefinition) []string {
+	if nd.Image == nil || !nd.MemoryLimit.IsEmpty() {
+		return nil
+	}
+	return []string{"no memory-limit given"}
+}
+
+func lintSingleInstanceStateful(nd *ComponentDefinition) []string {
+	if nd.Scale != nil && nd.Scale.Max > 1 {
+		return nil
+	}
+	for _, vc := range nd.Volumes {
+		if vc.Path != "" && vc.VolumeFrom == "" {
+			return []string{fmt.Sprintf("volume '%s' is used by a single instance only", vc.Path)}
+		}
+	}
+	return nil
+}
+
+func lintVolumeWithoutSize(nd *ComponentDefinition) []string {
+	messages := []string{}
+	for _, vc := range nd.Volumes {
+		if vc.Path != "" && vc.VolumeFrom == "" && vc.Size.Empty() {
+			messages = append(messages, fmt.Sprintf("volume '%s' has no size", vc.Path))
+		}
+	}
+	return messages
+}
+
+// lintUnusedPorts reports exported ports that are neither the target of a
+// link or expose definition, nor bound to a domain or used by a health check.
+func lintUnusedPorts(nds ComponentDefinitions) Warnings {
+	used := map[ComponentName]map[string]bool{}
+	markUsed := func(name ComponentName, port generictypes.DockerPort) {
+		if used[name] == nil {
+			used[name] = map[string]bool{}
+		}
+		used[name][port.String()] = true
+	}
+
+	for name, nd := range nds {
+		for _, link := range nd.Links {
+			if !link.LinksToSameService() {
+				continue
+			}
+			if implName, implPort, err := link.Resolve(nds); err == nil {
+				markUsed(implName, implPort)
+			}
+		}
+		for _, expose := range nd.Expose {
+			if implName, implPort, err := expose.Resolve(name, nds); err == nil {
+				markUsed(implName, implPort)
+			}
+		}
+		for _, ports := range nd.Domains {
+			for _, port := range ports {
+				markUsed(name, port)
+			}
+		}
+		if hc := nd.HealthCheck; hc != nil {
+			if hc.HTTP != nil {
+				markUsed(name, hc.HTTP.Port)
+			}
+			if hc.TCP != nil {
+				markUsed(name, hc.TCP.Port)
+			}
+		}
+	}
+
+	warnings := Warnings{}
+	for _, name := range orderedComponentKeys(nds) {
+		componentName := ComponentName(name)
+		nd := nds[componentName]
+		// Report port ranges once, not every port of them
+		for _, group := range groupPortRanges(nd.Ports, nd.PortRanges) {
+			unused := PortDefinitions{}
+			for _, port := range group.Ports {
+				if !used[componentName][port.String()] {
+					unused = append(unused, port)
+				}
+			}
+			if len(group.Ports) > 1 && len(unused) == len(group.Ports) {
+				warnings = append(warnings, Warning{
+					Component: componentName,
+					Message:   fmt.Sprintf("ports '%s' are exported, but not linked to, exposed, bound to a domain or health checked", group.Notation),
+				})
+				continue
+			}
+			for _, port := range unused {
+				warnings = append(warnings, Warning{
+					Component: componentName,
+					Message:   fmt.Sprintf("port '%s' is exported, but not linked to, exposed, bound to a domain or health checked", port),
+				})
+			}
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,182 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/user-config"
+)
+
+func lintIssueIDs(issues userconfig.LintIssues) []string {
+	ids := []string{}
+	for _, issue := range issues {
+		ids = append(ids, issue.Component.String()+":"+issue.Rule)
+	}
+	return ids
+}
+
+func TestLint(t *testing.T) {
+	list := []struct {
+		Config string
+		Issues []string
+	}{
+		// Nothing to complain about
+		{`{ "components": { "api": {
+			"image": "registry/namespace/api:1.0",
+			"memory-limit": "512M",
+			"ports": [ "80/tcp" ],
+			"domains": { "api.example.com": "80" }
+		}}}`, []string{}},
+		// Unpinned images
+		{`{ "components": { "api": { "image": "registry/namespace/api", "memory-limit": "512M" }}}`, []string{"api:unpinned-image"}},
+		{`{ "components": { "api": { "image": "registry/namespace/api:latest", "memory-limit": "512M" }}}`, []string{"api:unpinned-image"}},
+		// Missing memory-limit
+		{`{ "components": { "api": { "image": "registry/namespace/api:1.0" }}}`, []string{"api:missing-memory-limit"}},
+		// Stateful components and volumes
+		{`{ "components": { "db": {
+			"image": "registry/namespace/db:1.0",
+			"memory-limit": "512M",
+			"volumes": [ { "path": "/data", "size": "5 GB" } ]
+		}}}`, []string{"db:single-instance-stateful"}},
+		{`{ "components": { "db": {
+			"image": "registry/namespace/db:1.0",
+			"memory-limit": "512M",
+			"volumes": [ { "path": "/data", "size": "5 GB" } ],
+			"scale": { "min": 2, "max": 3 }
+		}}}`, []string{}},
+		{`{ "components": { "db": {
+			"image": "registry/namespace/db:1.0",
+			"memory-limit": "512M",
+			"volumes": [ { "path": "/data" } ],
+			"scale": { "max": 3 }
+		}}}`, []string{"db:volume-without-size"}},
+		// Unused ports
+		{`{ "components": {
+			"api": {
+				"image": "registry/namespace/api:1.0",
+				"memory-limit": "512M",
+				"ports": [ "80/tcp", "9000/tcp" ],
+				"links": [ { "component": "db", "target_port": "5432/tcp" } ],
+				"env": { "DB_HOST": "db" },
+				"domains": { "api.example.com": "80" }
+			},
+			"db": {
+				"image": "registry/namespace/db:1.0",
+				"memory-limit": "512M",
+				"ports": [ "5432/tcp" ]
+			}
+		}}`, []string{"api:unused-port"}},
+		// Unused port ranges are reported once
+		{`{ "components": { "api": {
+			"image": "registry/namespace/api:1.0",
+			"memory-limit": "512M",
+			"ports": [ "80/tcp", "10000-10100/udp" ],
+			"domains": { "api.example.com": "80" }
+		}}}`, []string{"api:unused-port"}},
+		// Ignored through x-lint-ignore and comment
+		{`{ "components": { "api": {
+			"image": "registry/namespace/api",
+			"x-lint-ignore": [ "missing-memory-limit" ]
+		}}}`, []string{"api:unpinned-image"}},
+		{`{ "components": { "api": {
+			"image": "registry/namespace/api",
+			"//": "Test image only.\nlint-ignore: unpinned-image, missing-memory-limit"
+		}}}`, []string{}},
+		{`{ "components": { "api": {
+			"image": "registry/namespace/api",
+			"x-lint-ignore": [ "all" ]
+		}}}`, []string{}},
+	}
+
+	for i, test := range list {
+		var def userconfig.ServiceDefinition
+		if err := json.Unmarshal([]byte(test.Config), &def); err != nil {
+			t.Fatalf("Test %d: json.Unmarshal failed: %v", i, err)
+		}
+
+		ids := lintIssueIDs(def.Lint())
+		if len(ids) != len(test.Issues) {
+			t.Fatalf("Test %d: expected issues %v, got %v", i, test.Issues, ids)
+		}
+		for j := range ids {
+			if ids[j] != test.Issues[j] {
+				t.Fatalf("Test %d: expected issues %v, got %v", i, test.Issues, ids)
+			}
+		}
+	}
+}
+
+func TestLintSeverities(t *testing.T) {
+	for _, rule := range userconfig.LintRules() {
+		switch rule.Severity {
+		case userconfig.LintSeverityError, userconfig.LintSeverityWarning, userconfig.LintSeverityInfo:
+		default:
+			t.Fatalf("rule '%s' has invalid severity '%s'", rule.ID, rule.Severity)
+		}
+	}
+
+	b := []byte(`{ "components": { "db": {
+		"image": "registry/namespace/db:1.0",
+		"memory-limit": "512M",
+		"volumes": [ { "path": "/data" } ],
+		"scale": { "max": 3 }
+	}}}`)
+	var def userconfig.ServiceDefinition
+	if err := json.Unmarshal(b, &def); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	issues := def.Lint()
+	if !issues.HasErrors() {
+		t.Fatalf("expected lint errors, got %v", issues)
+	}
+	if len(issues.BySeverity(userconfig.LintSeverityWarning)) != 0 {
+		t.Fatalf("expected no lint warnings, got %v", issues)
+	}
+}
+
+func TestLintIgnoreUnknownRule(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/a"].LintIgnore = []string{"missing-memory-limt"}
+
+	def.Components["component/b"].Comment = "lint-ignore: unused-prot"
+	if err := def.Validate(nil); err != nil {
+		t.Fatalf("expected validation to pass, got %v", err)
+	}
+
+	issues := def.Lint()
+	unknown := userconfig.LintIssues{}
+	for _, issue := range issues {
+		if issue.Rule == userconfig.LintRuleUnknownLintIgnore {
+			unknown = append(unknown, issue)
+		}
+	}
+	if len(unknown) != 2 {
+		t.Fatalf("expected 2 unknown-lint-ignore issues, got %v", issues)
+	}
+	if unknown[0].Component != "component/a" || !strings.Contains(unknown[0].Message, "missing-memory-limt") {
+		t.Fatalf("expected issue about 'missing-memory-limt' in component/a, got %v", unknown[0])
+	}
+	if unknown[1].Component != "component/b" || !strings.Contains(unknown[1].Message, "unused-prot") {
+		t.Fatalf("expected issue about 'unused-prot' in component/b, got %v", unknown[1])
+	}
+}
+
+func TestWarningsRespectLintIgnore(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/a"].Env = userconfig.EnvList{"DB=$POSTGRES_PORT_80_TCP_ADDR"}
+	if len(def.Warnings()) == 0 {
+		t.Fatalf("expected link-env warnings")
+	}
+
+	def.Components["component/a"].LintIgnore = []string{"link-env"}
+	if warnings := def.Warnings(); len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", warnings)
+	}
+
+	def.Components["component/a"].LintIgnore = nil
+	def.Components["component/a"].Comment = "lint-ignore: link-env"
+	if warnings := def.Warnings(); len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", warnings)
+	}
+}
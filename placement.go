@@ -0,0 +1,205 @@
+package userconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	SpreadByZone   = "zone"
+	SpreadByRegion = "region"
+)
+
+var nodeLabelKeyRegExp = regexp.MustCompile("^[a-zA-Z0-9]([a-zA-Z0-9._/-]*[a-zA-Z0-9])?$")
+
+// PlacementConstraints restrict the machines the instances of a component can
+// be placed on.
+type PlacementConstraints struct {
+	// Labels a machine must have, e.g. "disk=ssd".
+	NodeLabels []string `json:"node-labels,omitempty" description:"Labels a machine must have, e.g. 'disk=ssd'"`
+
+	// Failure domain to spread the instances across. Can be zone or region.
+	SpreadBy string `json:"spread-by,omitempty" description:"Failure domain to spread instances across. Can be zone or region"`
+
+	// Maximum number of instances on a single machine.
+	MaxPerMachine int `json:"max-per-machine,omitempty" description:"Maximum number of instances on a single machine"`
+
+	// Components whose instances should be placed on the same machines.
+	Affinity ComponentNames `json:"affinity,omitempty" description:"Components to place on the same machines"`
+
+	// Components whose instances must not be placed on the same machines.
+	AntiAffinity ComponentNames `json:"anti-affinity,omitempty" description:"Components to not place on the same machines"`
+}
+
+// machineConstraints returns the constraints on the machines only, without
+// the affinities to other components. The members of a pod are placed on
+// the same machines, so they share these.
+func (pc *PlacementConstraints) machineConstraints() *PlacementConstraints {
+	if pc == nil {
+		return &PlacementConstraints{}
+	}
+
+	return &PlacementConstraints{
+		NodeLabels:    append([]string(nil), pc.NodeLabels...),
+		SpreadBy:      pc.SpreadBy,
+		MaxPerMachine: pc.MaxPerMachine,
+	}
+}
+
+// setMachineConstraints replaces the constraints on the machines by those of
+// the given constraints.
+func (pc *PlacementConstraints) setMachineConstraints(other *PlacementConstraints) {
+	pc.NodeLabels = append([]string(nil), other.NodeLabels...)
+	pc.SpreadBy = other.SpreadBy
+	pc.MaxPerMachine = other.MaxPerMachine
+}
+
+// hasMachineConstraints returns true if any constraint on the machines is
+// set.
+func (pc *PlacementConstraints) hasMachineConstraints() bool {
+	return pc != nil && (len(pc.NodeLabels) > 0 || pc.SpreadBy != "" || pc.MaxPerMachine != 0)
+}
+
+// isEmpty returns true if no constraint is set at all.
+func (pc *PlacementConstraints) isEmpty() bool {
+	return !pc.hasMachineConstraints() && len(pc.Affinity) == 0 && len(pc.AntiAffinity) == 0
+}
+
+// String returns the marshalled and ordered string represantion of its own
+// incarnation. It is important to have the string represantion ordered, since
+// we use it to compare two PlacementConstraints when creating a diff. See
+// diff.go
+func (pc *PlacementConstraints) String() string {
+	if pc == nil {
+		return ""
+	}
+
+	ordered := *pc
+	ordered.NodeLabels = append([]string{}, pc.NodeLabels...)
+	sort.Strings(ordered.NodeLabels)
+	ordered.Affinity = sortedComponentNames(pc.Affinity)
+	ordered.AntiAffinity = sortedComponentNames(pc.AntiAffinity)
+
+	raw, err := json.Marshal(ordered)
+	if err != nil {
+		panic(fmt.Sprintf("%#v\n", mask(err)))
+	}
+
+	return string(raw)
+}
+
+// Equals returns true if both describe the same constraints.
+func (pc *PlacementConstraints) Equals(other *PlacementConstraints) bool {
+	return pc.String() == other.String()
+}
+
+func (pc *PlacementConstraints) validate(placement Placement) error {
+	keys := map[string]bool{}
+	for i, l := range pc.NodeLabels {
+		field := fmt.Sprintf("node-labels[%d]", i)
+		parts := strings.SplitN(l, "=", 2)
+		if len(parts) != 2 || !nodeLabelKeyRegExp.MatchString(parts[0]) || parts[1] == "" {
+			return newDiagnostic(InvalidScalingConfigError, "node label '%s' must be given as 'key=value'", l).withField(field).withValue(l)
+		}
+		if keys[parts[0]] {
+			return newDiagnostic(InvalidScalingConfigError, "node label '%s' is given more than once", parts[0]).withField(field).withValue(l)
+		}
+		keys[parts[0]] = true
+	}
+
+	switch pc.SpreadBy {
+	case "", SpreadByZone, SpreadByRegion:
+	default:
+		return newDiagnostic(InvalidScalingConfigError, "unknown value for spread-by: '%s'", pc.SpreadBy).withField("spread-by").withValue(pc.SpreadBy)
+	}
+
+	if pc.MaxPerMachine < 0 {
+		return newDiagnostic(InvalidScalingConfigError, "max-per-machine '%d' cannot be negative", pc.MaxPerMachine).withField("max-per-machine").withValue(pc.MaxPerMachine)
+	}
+	if pc.MaxPerMachine > 1 && placement == OnePerMachinePlacement {
+		return newDiagnostic(InvalidScalingConfigError, "max-per-machine '%d' conflicts with placement '%s'", pc.MaxPerMachine, placement).withField("max-per-machine").withValue(pc.MaxPerMachine)
+	}
+
+	for i, name := range pc.Affinity {
+		if err := name.Validate(); err != nil {
+			return newDiagnostic(InvalidScalingConfigError, "invalid affinity: %s", err.Error()).withField(fmt.Sprintf("affinity[%d]", i)).withValue(name).withUnderlying(err)
+		}
+	}
+	for i, name := range pc.AntiAffinity {
+		if err := name.Validate(); err != nil {
+			return newDiagnostic(InvalidScalingConfigError, "invalid affinity: %s", err.Error()).withField(fmt.Sprintf("anti-affinity[%d]", i)).withValue(name).withUnderlying(err)
+		}
+	}
+	for i, name := range pc.Affinity {
+		if pc.AntiAffinity.Contain(name) {
+			return newDiagnostic(InvalidScalingConfigError, "component '%s' cannot be in both affinity and anti-affinity", name.String()).withField(fmt.Sprintf("affinity[%d]", i)).withValue(name)
+		}
+	}
+
+	return nil
+}
+
+// validatePlacementConstraints checks that all components referenced by
+// affinity and anti-affinity exist and that no component has an
+// anti-affinity to itself or to a member of its own pod.
+func (nds ComponentDefinitions) validatePlacementConstraints() error {
+	for componentName, componentDef := range nds {
+		if componentDef.Scale == nil || componentDef.Scale.Constraints == nil {
+			continue
+		}
+		pc := componentDef.Scale.Constraints
+
+		refs := map[string]ComponentNames{
+			"scale.constraints.affinity":      pc.Affinity,
+			"scale.constraints.anti-affinity": pc.AntiAffinity,
+		}
+		for _, key := range []string{"scale.constraints.affinity", "scale.constraints.anti-affinity"} {
+			for i, name := range refs[key] {
+				field := fmt.Sprintf("%s[%d]", key, i)
+				if name == componentName {
+					return newDiagnostic(InvalidScalingConfigError, "component '%s' cannot reference itself in affinity or anti-affinity", componentName.String()).withComponent(componentName).withField(field).withValue(name)
+				}
+				if !nds.Contains(name) {
+					return newDiagnostic(InvalidScalingConfigError, "invalid affinity in component '%s': component '%s' does not exists", componentName.String(), name.String()).withComponent(componentName).withField(field).withValue(name)
+				}
+			}
+		}
+
+		if !nds.IsPartOfPod(componentName) {
+			continue
+		}
+		podComponents, err := nds.PodComponentsRecursive(componentName)
+		if err != nil {
+			return mask(err)
+		}
+		for i, name := range pc.AntiAffinity {
+			if podComponents.Contains(name) {
+				return newDiagnostic(InvalidScalingConfigError, "component '%s' cannot have an anti-affinity to '%s' in the same pod", componentName.String(), name.String()).withComponent(componentName).withField(fmt.Sprintf("scale.constraints.anti-affinity[%d]", i)).withValue(name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func sortedComponentNames(names ComponentNames) ComponentNames {
+	if names == nil {
+		return nil
+	}
+
+	list := []string{}
+	for _, n := range names {
+		list = append(list, n.String())
+	}
+	sort.Strings(list)
+
+	sorted := ComponentNames{}
+	for _, n := range list {
+		sorted = append(sorted, ComponentName(n))
+	}
+
+	return sorted
+}
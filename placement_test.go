@@ -0,0 +1,194 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/giantswarm/user-config"
+)
+
+func TestParsePlacementConstraints(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"db": {
+				"image": "registry/namespace/db:version",
+				"scale": {
+					"constraints": {
+						"node-labels": [ "disk=ssd" ],
+						"spread-by": "zone",
+						"max-per-machine": 1,
+						"anti-affinity": [ "cache" ]
+					}
+				}
+			},
+			"cache": {
+				"image": "registry/namespace/cache:version"
+			}
+		}
+	}`)
+
+	var serviceDef userconfig.ServiceDefinition
+	if err := json.Unmarshal(b, &serviceDef); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	pc := serviceDef.Components["db"].Scale.Constraints
+	if pc == nil || len(pc.NodeLabels) != 1 || pc.SpreadBy != userconfig.SpreadByZone || pc.MaxPerMachine != 1 || !pc.AntiAffinity.Contain("cache") {
+		t.Fatalf("invalid placement constraints: %s", pc.String())
+	}
+
+	if err := serviceDef.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+}
+
+func TestPlacementConstraintsValidation(t *testing.T) {
+	tests := []struct {
+		Scale *userconfig.ScaleDefinition
+		Valid bool
+	}{
+		// Valid ones
+		{&userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{NodeLabels: []string{"disk=ssd", "giantswarm.io/gpu=true"}}}, true},
+		{&userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{SpreadBy: userconfig.SpreadByRegion, MaxPerMachine: 2}}, true},
+		{&userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{Affinity: userconfig.ComponentNames{"component/b"}}}, true},
+		{&userconfig.ScaleDefinition{Placement: userconfig.OnePerMachinePlacement, Constraints: &userconfig.PlacementConstraints{MaxPerMachine: 1}}, true},
+
+		// Invalid ones
+		{&userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{NodeLabels: []string{"disk"}}}, false},
+		{&userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{NodeLabels: []string{"disk=ssd", "disk=hdd"}}}, false},
+		{&userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{SpreadBy: "planet"}}, false},
+		{&userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{MaxPerMachine: -1}}, false},
+		{&userconfig.ScaleDefinition{Placement: userconfig.OnePerMachinePlacement, Constraints: &userconfig.PlacementConstraints{MaxPerMachine: 2}}, false},
+		{&userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{Affinity: userconfig.ComponentNames{"component/unknown"}}}, false},
+		{&userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{AntiAffinity: userconfig.ComponentNames{"component/a"}}}, false},
+		{&userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{
+			Affinity:     userconfig.ComponentNames{"component/b"},
+			AntiAffinity: userconfig.ComponentNames{"component/b"},
+		}}, false},
+	}
+
+	for i, test := range tests {
+		def := ExampleDefinition()
+		def.Components["component/a"].Scale = test.Scale
+
+		err := def.Validate(nil)
+		if test.Valid && err != nil {
+			t.Fatalf("test %d: expected definition to be valid, got error: %#v", i, err)
+		}
+		if !test.Valid && !userconfig.IsInvalidScalingConfig(err) {
+			t.Fatalf("test %d: expected error to be InvalidScalingConfigError, got: %#v", i, err)
+		}
+	}
+}
+
+func TestPlacementConstraintsInPods(t *testing.T) {
+	service := testService()
+	service.Components["pod"] = setPod(&userconfig.ComponentDefinition{}, userconfig.PodChildren)
+	service.Components["pod/a"] = testComponent()
+	service.Components["pod/a"].Scale = &userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{NodeLabels: []string{"disk=ssd"}}}
+	service.Components["pod/b"] = testComponent()
+	service.Components["pod/b"].Scale = &userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{NodeLabels: []string{"disk=ssd"}}}
+	service.Components["other"] = testComponent()
+
+	if err := service.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+
+	// Different constraints
+	service.Components["pod/b"].Scale.Constraints = &userconfig.PlacementConstraints{NodeLabels: []string{"disk=hdd"}}
+	if err := service.Validate(nil); !userconfig.IsInvalidScalingConfig(err) {
+		t.Fatalf("expected error to be InvalidScalingConfigError, got: %#v", err)
+	}
+
+	// Anti-affinity to a member of the same pod
+	service.Components["pod/b"].Scale = nil
+	service.Components["pod/a"].Scale.Constraints = &userconfig.PlacementConstraints{AntiAffinity: userconfig.ComponentNames{"pod/b"}}
+	if err := service.Validate(nil); !userconfig.IsInvalidScalingConfig(err) {
+		t.Fatalf("expected error to be InvalidScalingConfigError, got: %#v", err)
+	}
+
+	service.Components["pod/a"].Scale.Constraints = &userconfig.PlacementConstraints{AntiAffinity: userconfig.ComponentNames{"other"}}
+	if err := service.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+}
+
+func TestPlacementConstraintsSharedInPods(t *testing.T) {
+	service := testService()
+	service.Components["pod"] = setPod(&userconfig.ComponentDefinition{}, userconfig.PodChildren)
+	service.Components["pod/a"] = testComponent()
+	service.Components["pod/a"].Scale = &userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{NodeLabels: []string{"disk=ssd"}}}
+	service.Components["pod/b"] = testComponent()
+
+	valCtx := NewValidationContext()
+	if err := service.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+	constraints := service.Components["pod/b"].Scale.Constraints
+	if !constraints.Equals(service.Components["pod/a"].Scale.Constraints) {
+		t.Fatalf("expected constraints to be shared in pod, got: %s", constraints.String())
+	}
+	if err := service.Validate(valCtx); err != nil {
+		t.Fatalf("validating service failed: %#v", err)
+	}
+}
+
+func TestPlacementConstraintsSharedInPodsRoundTrip(t *testing.T) {
+	service := testService()
+	service.Components["pod"] = setPod(&userconfig.ComponentDefinition{}, userconfig.PodChildren)
+	service.Components["pod/a"] = testComponent()
+	service.Components["pod/a"].Scale = &userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{
+		NodeLabels: []string{"disk=ssd"},
+		SpreadBy:   userconfig.SpreadByZone,
+		Affinity:   userconfig.ComponentNames{"pod/b"},
+	}}
+	service.Components["pod/b"] = testComponent()
+	expected := service.Components["pod/a"].Scale.Constraints.String()
+
+	valCtx := NewValidationContext()
+	if err := service.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+	if constraints := service.Components["pod/b"].Scale.Constraints; len(constraints.Affinity) != 0 || constraints.SpreadBy != userconfig.SpreadByZone {
+		t.Fatalf("expected machine constraints only to be shared in pod, got: %s", constraints.String())
+	}
+	if err := service.Validate(valCtx); err != nil {
+		t.Fatalf("validating service failed: %#v", err)
+	}
+
+	hidden, err := service.HideDefaults(valCtx)
+	if err != nil {
+		t.Fatalf("hiding defaults failed: %#v", err)
+	}
+	if scale := hidden.Components["pod/b"].Scale; scale != nil {
+		t.Fatalf("expected shared constraints to be hidden, got: %#v", scale)
+	}
+	if constraints := hidden.Components["pod/a"].Scale.Constraints.String(); constraints != expected {
+		t.Fatalf("expected constraints '%s', got '%s'", expected, constraints)
+	}
+	if err := hidden.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults again failed: %#v", err)
+	}
+	if err := hidden.Validate(valCtx); err != nil {
+		t.Fatalf("validating service with defaults set again failed: %#v", err)
+	}
+}
+
+func TestPlacementConstraintsDiff(t *testing.T) {
+	oldDef := ExampleDefinition()
+	oldDef.Components["component/a"].Scale = &userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{NodeLabels: []string{"a=1", "b=2"}}}
+	newDef := ExampleDefinition()
+	newDef.Components["component/a"].Scale = &userconfig.ScaleDefinition{Constraints: &userconfig.PlacementConstraints{NodeLabels: []string{"b=2", "a=1"}}}
+
+	// Order does not matter
+	diffInfos := userconfig.DiffInfosByType(userconfig.ServiceDiff(oldDef, newDef), userconfig.DiffTypeComponentScaleConstraintsUpdated)
+	if len(diffInfos) != 0 {
+		t.Fatalf("expected no diff: %#v", diffInfos)
+	}
+
+	newDef.Components["component/a"].Scale.Constraints.SpreadBy = userconfig.SpreadByZone
+	diffInfos = userconfig.DiffInfosByType(userconfig.ServiceDiff(oldDef, newDef), userconfig.DiffTypeComponentScaleConstraintsUpdated)
+	if len(diffInfos) != 1 {
+		t.Fatalf("expected one diff: %#v", diffInfos)
+	}
+}
@@ -0,0 +1,372 @@
+package userconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/giantswarm/generic-types-go"
+)
+
+// maxPortNameLength is the maximum length of a port name, as used for
+// service names in /etc/services.
+const maxPortNameLength = 15
+
+var portNameRegExp = regexp.MustCompile("^[a-z]([a-z0-9-]*[a-z0-9])?$")
+
+// PortName is the name of a port of a component, e.g. "http". Links, expose
+// and domains can refer to a port by its name instead of its number.
+type PortName string
+
+func (pn PortName) String() string {
+	return string(pn)
+}
+
+func (pn PortName) Empty() bool {
+	return pn == ""
+}
+
+func (pn PortName) Validate() error {
+	if len(pn) > maxPortNameLength {
+		return maskf(InvalidPortConfigError, "port name '%s' must not be longer than %d characters", pn, maxPortNameLength)
+	}
+	if !portNameRegExp.MatchString(pn.String()) {
+		return maskf(InvalidPortConfigError, "port name '%s' must consist of lower case letters, digits and '-', starting with a letter", pn)
+	}
+	return nil
+}
+
+// PortNames maps port names to ports of a component, e.g. "http" => "8080/tcp".
+type PortNames map[PortName]generictypes.DockerPort
+
+// String returns the marshalled and ordered string represantion of its own
+// incarnation. It is important to have the string represantion ordered, since
+// we use it to compare two PortNames when creating a diff. See diff.go
+func (pns PortNames) String() string {
+	simple := map[string]string{}
+	for name, port := range pns {
+		simple[name.String()] = port.String()
+	}
+
+	raw, err := json.Marshal(simple)
+	if err != nil {
+		panic(fmt.Sprintf("%#v\n", mask(err)))
+	}
+
+	return string(raw)
+}
+
+// nameOf returns the name of the given port, if any.
+func (pns PortNames) nameOf(port generictypes.DockerPort) (PortName, bool) {
+	for name, p := range pns {
+		if p.Equals(port) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// nameAny returns true if any of the given ports has a name.
+func (pns PortNames) nameAny(ports PortDefinitions) bool {
+	for _, port := range ports {
+		if _, ok := pns.nameOf(port); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks that all names are valid and refer to distinct exported
+// ports.
+func (pns PortNames) validate(exportedPorts PortDefinitions) error {
+	seen := map[string]PortName{}
+	for name, port := range pns {
+		if err := name.Validate(); err != nil {
+			return mask(err)
+		}
+		if !exportedPorts.contains(port) {
+			return maskf(InvalidPortConfigError, "port '%s' named '%s' must be exported", port, name)
+		}
+		if other, ok := seen[port.String()]; ok {
+			return maskf(InvalidPortConfigError, "port '%s' cannot be named both '%s' and '%s'", port, other, name)
+		}
+		seen[port.String()] = name
+	}
+
+	return nil
+}
+
+// portByName returns the port of this component with the given name.
+func (nd *ComponentDefinition) portByName(name PortName) (generictypes.DockerPort, error) {
+	port, ok := nd.PortNames[name]
+	if !ok {
+		return generictypes.DockerPort{}, maskf(PortNotFoundError, "port name '%s' not found", name)
+	}
+	return port, nil
+}
+
+// parsePortOrName parses the given JSON value as port, e.g. "8080/tcp" or
+// 8080, or as port name, e.g. "http".
+func parsePortOrName(data []byte) (generictypes.DockerPort, PortName, error) {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		if _, err := generictypes.ParseDockerPort(str); err != nil && portNameRegExp.MatchString(str) {
+			name := PortName(str)
+			if err := name.Validate(); err != nil {
+				return generictypes.DockerPort{}, "", mask(err)
+			}
+			return generictypes.DockerPort{}, name, nil
+		}
+	}
+
+	var port generictypes.DockerPort
+	if err := json.Unmarshal(data, &port); err != nil {
+		return generictypes.DockerPort{}, "", mask(err)
+	}
+	return port, "", nil
+}
+
+// portOrName returns the value to marshal for a port that may be referred to
+// by name.
+func portOrName(port generictypes.DockerPort, name PortName) interface{} {
+	if !name.Empty() {
+		return name
+	}
+	return port
+}
+
+// namedPortDefinitions is the JSON representation of the ports of a
+// component, that may carry names. Besides the formats of PortDefinitions,
+// the following formats are supported:
+//   - { "http": "8080/tcp", "metrics": 9100 }
+//   - [ { "http": "8080/tcp" }, "9000" ]
+type namedPortDefinitions struct {
+	Ports  PortDefinitions
+	Names  PortNames
+	Ranges []string
+}
+
+func (npd *namedPortDefinitions) UnmarshalJSON(data []byte) error {
+	npd.Ports = PortDefinitions{}
+
+	if len(data) > 0 && data[0] == '{' {
+		if err := npd.addNamed(data); err != nil {
+			return mask(err)
+		}
+		return nil
+	}
+
+	if len(data) == 0 || data[0] != '[' {
+		if err := json.Unmarshal(data, &npd.Ports); err != nil {
+			return mask(err)
+		}
+		npd.addRange(data)
+		return nil
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		return mask(err)
+	}
+	for _, raw := range list {
+		if len(raw) > 0 && raw[0] == '{' {
+			if err := npd.addNamed(raw); err != nil {
+				return mask(err)
+			}
+			continue
+		}
+
+		var ports PortDefinitions
+		if err := json.Unmarshal(raw, &ports); err != nil {
+			return mask(err)
+		}
+		npd.Ports = append(npd.Ports, ports...)
+		npd.addRange(raw)
+	}
+
+	return nil
+}
+
+// addRange remembers the given port, if it is a port range, e.g.
+// "10000-10100/udp".
+func (npd *namedPortDefinitions) addRange(data []byte) {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil && isPortRange(str) {
+		npd.Ranges = append(npd.Ranges, str)
+	}
+}
+
+// addNamed adds the ports of the given name => port object, ordered by name.
+func (npd *namedPortDefinitions) addNamed(data []byte) error {
+	var local map[string]generictypes.DockerPort
+	if err := json.Unmarshal(data, &local); err != nil {
+		return mask(err)
+	}
+
+	names := []string{}
+	for name := range local {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if npd.Names == nil {
+		npd.Names = PortNames{}
+	}
+	for _, name := range names {
+		if _, ok := npd.Names[PortName(name)]; ok {
+			return maskf(InvalidPortConfigError, "duplicate port name '%s'", name)
+		}
+		npd.Names[PortName(name)] = local[name]
+		npd.Ports = append(npd.Ports, local[name])
+	}
+
+	return nil
+}
+
+// MarshalJSON marshals named ports as name => port objects, in the order of
+// the ports. Port ranges are marshalled the way they were given, e.g.
+// "10000-10100/udp", other ports one by one.
+func (npd namedPortDefinitions) MarshalJSON() ([]byte, error) {
+	list := []interface{}{}
+	for _, group := range groupPortRanges(npd.Ports, npd.Ranges) {
+		if len(group.Ports) > 1 && !npd.Names.nameAny(group.Ports) {
+			list = append(list, group.Notation)
+			continue
+		}
+		for _, port := range group.Ports {
+			if name, ok := npd.Names.nameOf(port); ok {
+				list = append(list, map[string]generictypes.DockerPort{name.String(): port})
+			} else {
+				list = append(list, port)
+			}
+		}
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return nil, mask(err)
+	}
+	return data, nil
+}
+
+type componentDefinitionCopy ComponentDefinition
+
+// UnmarshalJSON performs custom unmarshalling to support named ports and
+// domain settings.
+func (nd *ComponentDefinition) UnmarshalJSON(data []byte) error {
+	var local struct {
+		componentDefinitionCopy
+		Ports   *namedPortDefinitions `json:"ports,omitempty"`
+		Domains *domainDefinitions    `json:"domains,omitempty"`
+	}
+	if err := decodeStrict(data, &local); err != nil {
+		return mask(err)
+	}
+
+	*nd = ComponentDefinition(local.componentDefinitionCopy)
+	if local.Ports != nil {
+		nd.Ports = local.Ports.Ports
+		nd.PortNames = local.Ports.Names
+		nd.PortRanges = local.Ports.Ranges
+	}
+	if local.Domains != nil {
+		nd.Domains = local.Domains.Ports
+		nd.DomainSettings = local.Domains.Settings
+	}
+
+	return nil
+}
+
+// MarshalJSON performs custom marshalling to support named ports, port
+// ranges and domain settings. Without those, the ports and domains are
+// marshalled at their usual position, so the generated name of existing
+// services does not change.
+func (nd ComponentDefinition) MarshalJSON() ([]byte, error) {
+	if len(nd.PortNames) == 0 && len(nd.PortRanges) == 0 && len(nd.DomainSettings) == 0 {
+		data, err := json.Marshal(componentDefinitionCopy(nd))
+		if err != nil {
+			return nil, mask(err)
+		}
+		return data, nil
+	}
+
+	local := struct {
+		componentDefinitionCopy
+		Ports   *namedPortDefinitions `json:"ports,omitempty"`
+		Domains *domainDefinitions    `json:"domains,omitempty"`
+	}{
+		componentDefinitionCopy: componentDefinitionCopy(nd),
+	}
+	if len(nd.Ports) > 0 {
+		local.Ports = &namedPortDefinitions{Ports: nd.Ports, Names: nd.PortNames, Ranges: nd.PortRanges}
+	}
+	if len(nd.Domains) > 0 {
+		local.Domains = &domainDefinitions{Ports: nd.Domains, Settings: nd.DomainSettings}
+	}
+
+	data, err := json.Marshal(local)
+	if err != nil {
+		return nil, mask(err)
+	}
+	return data, nil
+}
+
+// resolvePortNames sets the port of every link, expose and domain that
+// refers to a port by name. Links to other services cannot be resolved here.
+func (nds ComponentDefinitions) resolvePortNames() error {
+	for componentName, component := range nds {
+		for domain, ds := range component.DomainSettings {
+			if ds.PortName.Empty() {
+				continue
+			}
+			port, err := component.portByName(ds.PortName)
+			if err != nil {
+				return newDiagnostic(InvalidDomainDefinitionError, "domain '%s' refers to unknown port name '%s'", domain, ds.PortName).withComponent(componentName).withField(fmt.Sprintf("domains[%q].port", domain)).withValue(ds.PortName).withSuggestion(ds.PortName.String(), portNameCandidates(component))
+			}
+			if component.Domains == nil {
+				component.Domains = V2DomainDefinitions{}
+			}
+			component.Domains[domain] = PortDefinitions{port}
+		}
+
+		for i, ed := range component.Expose {
+			if ed.PortName.Empty() && ed.TargetPortName.Empty() {
+				continue
+			}
+			implName := ed.ImplementationComponentName(componentName)
+			implComponent, err := nds.ComponentByName(implName)
+			if err != nil {
+				// Reported by validateExpose
+				continue
+			}
+			if !ed.PortName.Empty() {
+				if ed.Port, err = implComponent.portByName(ed.PortName); err != nil {
+					return newDiagnostic(InvalidComponentDefinitionError, "invalid expose to component '%s': unknown port name '%s'", implName, ed.PortName).withComponent(componentName).withField(fmt.Sprintf("expose[%d].port", i)).withValue(ed.PortName).withSuggestion(ed.PortName.String(), portNameCandidates(implComponent))
+				}
+			}
+			if !ed.TargetPortName.Empty() {
+				if ed.TargetPort, err = implComponent.portByName(ed.TargetPortName); err != nil {
+					return newDiagnostic(InvalidComponentDefinitionError, "invalid expose to component '%s': unknown port name '%s'", implName, ed.TargetPortName).withComponent(componentName).withField(fmt.Sprintf("expose[%d].target_port", i)).withValue(ed.TargetPortName).withSuggestion(ed.TargetPortName.String(), portNameCandidates(implComponent))
+				}
+			}
+			component.Expose[i] = ed
+		}
+
+		for i, link := range component.Links {
+			if link.TargetPortName.Empty() || link.LinksToOtherService() {
+				continue
+			}
+			targetComponent, err := nds.ComponentByName(link.Component)
+			if err != nil {
+				// Reported by validateLinks
+				continue
+			}
+			if component.Links[i].TargetPort, err = targetComponent.portByName(link.TargetPortName); err != nil {
+				return newDiagnostic(InvalidLinkDefinitionError, "invalid link to component '%s': unknown port name '%s'", link.Component, link.TargetPortName).withComponent(componentName).withField(fmt.Sprintf("links[%d].target_port", i)).withValue(link.TargetPortName).withSuggestion(link.TargetPortName.String(), portNameCandidates(targetComponent))
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/generic-types-go"
+	"github.com/giantswarm/user-config"
+)
+
+var namedPortsService = []byte(`{
+	"components": {
+		"api": {
+			"image": "busybox",
+			"ports": { "http": "8080/tcp", "metrics": 9100 },
+			"domains": {
+				"api.com": { "port": "http" }
+			}
+		},
+		"web": {
+			"image": "busybox",
+			"ports": [ { "www": 80 }, "443" ],
+			"links": [
+				{ "component": "api", "target_port": "http" }
+			]
+		},
+		"lb": {
+			"image": "busybox",
+			"ports": [ "80" ],
+			"expose": [
+				{ "port": "8000/tcp", "component": "lb/app", "target_port": "app" }
+			]
+		},
+		"lb/app": {
+			"image": "busybox",
+			"ports": [ { "app": "3000" } ]
+		}
+	}
+}`)
+
+func TestNamedPortsParse(t *testing.T) {
+	def, err := userconfig.ParseServiceDefinition(namedPortsService)
+	if err != nil {
+		t.Fatalf("ParseServiceDefinition failed: %v", err)
+	}
+
+	api := def.Components["api"]
+	if len(api.Ports) != 2 || len(api.PortNames) != 2 {
+		t.Fatalf("expected 2 named ports, got %v %v", api.Ports, api.PortNames)
+	}
+	if !api.PortNames["metrics"].Equals(generictypes.MustParseDockerPort("9100/tcp")) {
+		t.Fatalf("invalid port for metrics: %v", api.PortNames["metrics"])
+	}
+	web := def.Components["web"]
+	if len(web.Ports) != 2 || len(web.PortNames) != 1 {
+		t.Fatalf("expected 2 ports, 1 named, got %v %v", web.Ports, web.PortNames)
+	}
+	if web.Links[0].TargetPortName != "http" {
+		t.Fatalf("expected link to port name 'http', got '%s'", web.Links[0].TargetPortName)
+	}
+
+	// Links resolve names without validation
+	name, port, err := web.Links[0].Resolve(def.Components)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if name != "api" || !port.Equals(generictypes.MustParseDockerPort("8080/tcp")) {
+		t.Fatalf("invalid resolve result: %s %s", name, port)
+	}
+	lb := def.Components["lb"]
+	name, port, err = lb.Expose[0].Resolve("lb", def.Components)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if name != "lb/app" || !port.Equals(generictypes.MustParseDockerPort("3000/tcp")) {
+		t.Fatalf("invalid resolve result: %s %s", name, port)
+	}
+
+	// Validation resolves all names
+	if err := def.Validate(nil); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !web.Links[0].TargetPort.Equals(generictypes.MustParseDockerPort("8080/tcp")) {
+		t.Fatalf("link port not resolved: %v", web.Links[0].TargetPort)
+	}
+	if ports := api.Domains["api.com"]; len(ports) != 1 || !ports[0].Equals(generictypes.MustParseDockerPort("8080/tcp")) {
+		t.Fatalf("domain port not resolved: %v", api.Domains["api.com"])
+	}
+
+	// Names are kept when marshalling
+	data, err := json.Marshal(def)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for _, expected := range []string{`{"http":"8080/tcp"}`, `"target_port":"http"`, `"target_port":"app"`, `"port":"http"`} {
+		if !strings.Contains(string(data), expected) {
+			t.Fatalf("expected '%s' in '%s'", expected, string(data))
+		}
+	}
+	if _, err := userconfig.ParseServiceDefinition(data); err != nil {
+		t.Fatalf("ParseServiceDefinition of marshalled definition failed: %v", err)
+	}
+}
+
+func TestNamedPortsInvalid(t *testing.T) {
+	list := []string{
+		// Invalid names
+		`{ "components": { "a": { "image": "busybox", "ports": { "http_1": 80 } } } }`,
+		`{ "components": { "a": { "image": "busybox", "ports": { "very-long-port-name": 80 } } } }`,
+		// Duplicate names
+		`{ "components": { "a": { "image": "busybox", "ports": [ { "http": 80 }, { "http": 81 } ] } } }`,
+	}
+
+	for i, s := range list {
+		def, err := userconfig.ParseServiceDefinition([]byte(s))
+		if err == nil {
+			err = def.Validate(nil)
+		}
+		if err == nil {
+			t.Fatalf("Test %d: invalid named ports considered valid", i)
+		}
+	}
+}
+
+func TestNamedPortsValidation(t *testing.T) {
+	http := generictypes.MustParseDockerPort("80/tcp")
+	list := []struct {
+		Name   string
+		Modify func(def *userconfig.ServiceDefinition)
+		Check  func(err error) bool
+	}{
+		{"name of unexported port", func(def *userconfig.ServiceDefinition) {
+			def.Components["component/a"].PortNames = userconfig.PortNames{"http": generictypes.MustParseDockerPort("81/tcp")}
+		}, userconfig.IsInvalidPortConfig},
+		{"two names for one port", func(def *userconfig.ServiceDefinition) {
+			def.Components["component/a"].PortNames = userconfig.PortNames{"http": http, "www": http}
+		}, userconfig.IsInvalidPortConfig},
+		{"link to unknown name", func(def *userconfig.ServiceDefinition) {
+			def.Components["component/a"].Links = userconfig.LinkDefinitions{{Component: "component/b", TargetPortName: "http"}}
+		}, userconfig.IsInvalidLinkDefinition},
+		{"domain with unknown name", func(def *userconfig.ServiceDefinition) {
+			def.Components["component/a"].Domains = userconfig.V2DomainDefinitions{"a.com": nil}
+			def.Components["component/a"].DomainSettings = userconfig.V2DomainSettings{"a.com": {PortName: "http"}}
+		}, userconfig.IsInvalidDomainDefinition},
+	}
+
+	for _, test := range list {
+		def := ExampleDefinition()
+		test.Modify(&def)
+		err := def.Validate(nil)
+		if err == nil {
+			t.Fatalf("%s: considered valid", test.Name)
+		}
+		if !test.Check(err) {
+			t.Fatalf("%s: unexpected error %v", test.Name, err)
+		}
+	}
+}
+
+func TestDiffPortNames(t *testing.T) {
+	oldDef := ExampleDefinition()
+	newDef := ExampleDefinition()
+	newDef.Components["component/a"].PortNames = userconfig.PortNames{"http": generictypes.MustParseDockerPort("80/tcp")}
+
+	diffInfos := userconfig.ServiceDiff(oldDef, newDef)
+	if len(diffInfos) != 1 {
+		t.Fatalf("expected 1 diff, got %v", diffInfos)
+	}
+	if diffInfos[0].Type != userconfig.DiffTypeComponentPortNamesUpdated {
+		t.Fatalf("expected port names diff, got %s", diffInfos[0].Type)
+	}
+	if len(diffInfos.RequiringRestart()) != 0 {
+		t.Fatalf("expected port names diff to not require a restart")
+	}
+}
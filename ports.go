@@ -4,30 +4,36 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/giantswarm/generic-types-go"
-	"github.com/juju/errgo"
 )
 
+// portRangeSeparator separates the first and the last port of a port range,
+// e.g. "10000-10100/udp".
+const portRangeSeparator = "-"
+
+// maxPortRangeSize is the maximum number of ports of a single port range.
+const maxPortRangeSize = 1000
+
 type PortDefinitions []generictypes.DockerPort
-type portDefinitions PortDefinitions
 
 // Validate tries to validate the current PortDefinitions. If valCtx is nil,
 // nothing can be validated. The given valCtx must provide at least one
-// protocol, or Validate returns an error. The currently valid one should only
-// be TCP.
+// protocol, or Validate returns an error, e.g. TCP and UDP.
 func (pds PortDefinitions) Validate(valCtx *ValidationContext) error {
 	if valCtx == nil {
 		return nil
 	}
 
 	if len(valCtx.Protocols) == 0 {
-		return errgo.Newf("missing protocol in validation context")
+		return newDiagnostic(MissingValidationContextError, "missing protocol in validation context")
 	}
 
 	for _, port := range pds {
 		if !contains(valCtx.Protocols, port.Protocol) {
-			return maskf(InvalidPortConfigError, "invalid protocol '%s' for port '%s', expected one of %v", port.Protocol, port.Port, valCtx.Protocols)
+			return newDiagnostic(InvalidPortConfigError, "invalid protocol '%s' for port '%s', expected one of %v", port.Protocol, port.Port, valCtx.Protocols).withValue(port)
 		}
 	}
 
@@ -47,15 +53,140 @@ func (pds *PortDefinitions) UnmarshalJSON(data []byte) error {
 		data = newData
 	}
 
-	var local portDefinitions
+	var local []json.RawMessage
 	if err := json.Unmarshal(data, &local); err != nil {
 		return mask(err)
 	}
-	*pds = PortDefinitions(local)
+
+	result := PortDefinitions{}
+	for _, raw := range local {
+		var str string
+		if err := json.Unmarshal(raw, &str); err == nil && isPortRange(str) {
+			// Port range, e.g. "10000-10100/udp"
+			ports, err := ParsePortRange(str)
+			if err != nil {
+				return mask(err)
+			}
+			result = append(result, ports...)
+			continue
+		}
+
+		var port generictypes.DockerPort
+		if err := json.Unmarshal(raw, &port); err != nil {
+			return mask(err)
+		}
+		result = append(result, port)
+	}
+	*pds = result
 
 	return nil
 }
 
+// isPortRange returns true if the given string looks like a port range,
+// e.g. "10000-10100/udp".
+func isPortRange(s string) bool {
+	return strings.Contains(s, portRangeSeparator)
+}
+
+// ParsePortRange parses a single port or a range of ports, e.g.
+// "10000-10100/udp", and returns all ports in it. The protocol applies to
+// all ports of the range.
+func ParsePortRange(s string) (PortDefinitions, error) {
+	if !isPortRange(s) {
+		port, err := generictypes.ParseDockerPort(s)
+		if err != nil {
+			return nil, newDiagnostic(InvalidPortConfigError, err.Error()).withValue(s).withUnderlying(err)
+		}
+		return PortDefinitions{port}, nil
+	}
+
+	numbers, protocol := s, ""
+	if i := strings.Index(s, "/"); i >= 0 {
+		numbers, protocol = s[:i], s[i:]
+	}
+	parts := strings.Split(numbers, portRangeSeparator)
+	if len(parts) != 2 {
+		return nil, newDiagnostic(InvalidPortConfigError, "invalid port range '%s'", s).withValue(s)
+	}
+
+	bounds := []int{}
+	for _, part := range parts {
+		if _, err := generictypes.ParseDockerPort(part + protocol); err != nil {
+			return nil, newDiagnostic(InvalidPortConfigError, "invalid port range '%s': %s", s, err.Error()).withValue(s).withUnderlying(err)
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, newDiagnostic(InvalidPortConfigError, "invalid port range '%s'", s).withValue(s)
+		}
+		bounds = append(bounds, n)
+	}
+	if bounds[0] > bounds[1] {
+		return nil, newDiagnostic(InvalidPortConfigError, "invalid port range '%s': first port must not be greater than last port", s).withValue(s)
+	}
+	if bounds[1]-bounds[0]+1 > maxPortRangeSize {
+		return nil, newDiagnostic(InvalidPortConfigError, "invalid port range '%s': must not contain more than %d ports", s, maxPortRangeSize).withValue(s)
+	}
+
+	ports := PortDefinitions{}
+	for n := bounds[0]; n <= bounds[1]; n++ {
+		port, err := generictypes.ParseDockerPort(strconv.Itoa(n) + protocol)
+		if err != nil {
+			return nil, newDiagnostic(InvalidPortConfigError, "invalid port range '%s': %s", s, err.Error()).withValue(s).withUnderlying(err)
+		}
+		ports = append(ports, port)
+	}
+
+	return ports, nil
+}
+
+// portGroup is a single port or a port range of PortDefinitions, e.g.
+// "10000-10100/udp", with the ports in it.
+type portGroup struct {
+	Notation string
+	Ports    PortDefinitions
+}
+
+// groupPortRanges groups the given ports by the given port ranges, e.g.
+// "10000-10100/udp", if they contain all ports of a range in order. All other
+// ports form groups of their own.
+func groupPortRanges(pds PortDefinitions, ranges []string) []portGroup {
+	byFirstPort := map[string]portGroup{}
+	for _, r := range ranges {
+		ports, err := ParsePortRange(r)
+		if err != nil || len(ports) == 0 {
+			continue
+		}
+		byFirstPort[ports[0].String()] = portGroup{Notation: r, Ports: ports}
+	}
+
+	groups := []portGroup{}
+	for i := 0; i < len(pds); {
+		if group, ok := byFirstPort[pds[i].String()]; ok && pds[i:].hasPrefix(group.Ports) {
+			groups = append(groups, group)
+			i += len(group.Ports)
+			continue
+		}
+		groups = append(groups, portGroup{Notation: pds[i].String(), Ports: pds[i : i+1]})
+		i++
+	}
+
+	return groups
+}
+
+// hasPrefix returns true if the given ports are the first ports of pds, in
+// order.
+func (pds PortDefinitions) hasPrefix(ports PortDefinitions) bool {
+	if len(ports) > len(pds) {
+		return false
+	}
+	for i, port := range ports {
+		if !pds[i].Equals(port) {
+			return false
+		}
+	}
+	return true
+}
+
 // String returns the marshalled and ordered string represantion of its own
 // incarnation. It is important to have the string represantion ordered, since
 // we use it to compare two PortDefinitions when creating a diff. See diff.go
@@ -87,6 +218,19 @@ func (pds PortDefinitions) contains(port generictypes.DockerPort) bool {
 	return false
 }
 
+// protocolMismatch returns a port of this list that has the same number as the
+// given port, but a different protocol, if any. It is used to explain why
+// a port is not found.
+func (pds PortDefinitions) protocolMismatch(port generictypes.DockerPort) (generictypes.DockerPort, bool) {
+	for _, pd := range pds {
+		if pd.Port == port.Port && pd.Protocol != port.Protocol {
+			return pd, true
+		}
+	}
+
+	return generictypes.DockerPort{}, false
+}
+
 func contains(protocols []string, protocol string) bool {
 	for _, p := range protocols {
 		if p == protocol {
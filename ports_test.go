@@ -2,6 +2,7 @@ package userconfig_test
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/giantswarm/generic-types-go"
@@ -17,6 +18,10 @@ func TestValidPortsValues(t *testing.T) {
 		{`"80/tcp"`, userconfig.PortDefinitions{generictypes.MustParseDockerPort("80")}},
 		{`["80/tcp","81/tcp"]`, userconfig.PortDefinitions{generictypes.MustParseDockerPort("80"), generictypes.MustParseDockerPort("81")}},
 		{`[80,"81/tcp","82"]`, userconfig.PortDefinitions{generictypes.MustParseDockerPort("80"), generictypes.MustParseDockerPort("81"), generictypes.MustParseDockerPort("82")}},
+		{`"53/udp"`, userconfig.PortDefinitions{generictypes.MustParseDockerPort("53/udp")}},
+		{`"10000-10002/udp"`, userconfig.PortDefinitions{generictypes.MustParseDockerPort("10000/udp"), generictypes.MustParseDockerPort("10001/udp"), generictypes.MustParseDockerPort("10002/udp")}},
+		{`[80,"8000-8001"]`, userconfig.PortDefinitions{generictypes.MustParseDockerPort("80"), generictypes.MustParseDockerPort("8000/tcp"), generictypes.MustParseDockerPort("8001/tcp")}},
+		{`"90-90/udp"`, userconfig.PortDefinitions{generictypes.MustParseDockerPort("90/udp")}},
 	}
 
 	for _, test := range list {
@@ -39,6 +44,11 @@ func TestInvalidPortsValues(t *testing.T) {
 	list := []string{
 		``,
 		`{"field":"foo"}`,
+		`"90-80"`,
+		`"80-90-100"`,
+		`"80-x/udp"`,
+		`"80-90/foo"`,
+		`"1-40000/udp"`,
 	}
 
 	for _, s := range list {
@@ -83,3 +93,163 @@ func TestUnmarshalV2PortsFullService(t *testing.T) {
 		t.Fatalf("Unmarshal failed: %v", err)
 	}
 }
+
+func TestUnmarshalV2PortRangesFullService(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"component1": {
+				"image": "busybox",
+				"ports": "10000-10009/udp"
+			},
+			"component2": {
+				"image": "busybox",
+				"ports": [ "53/udp", 80, "8000-8004" ]
+			}
+		}
+	}`)
+
+	def, err := userconfig.ParseServiceDefinition(b)
+	if err != nil {
+		t.Fatalf("ParseServiceDefinition failed: %v", err)
+	}
+	if n := len(def.Components["component1"].Ports); n != 10 {
+		t.Fatalf("expected 10 ports, got %d", n)
+	}
+	if n := len(def.Components["component2"].Ports); n != 7 {
+		t.Fatalf("expected 7 ports, got %d", n)
+	}
+}
+
+func TestMarshalV2PortRanges(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"component1": {
+				"image": "busybox",
+				"ports": [ "53/udp", "10000-10999/udp", { "http": 80 } ]
+			}
+		}
+	}`)
+
+	def, err := userconfig.ParseServiceDefinition(b)
+	if err != nil {
+		t.Fatalf("ParseServiceDefinition failed: %v", err)
+	}
+	data, err := json.Marshal(def)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"ports":["53/udp","10000-10999/udp",{"http":"80/tcp"}]`) {
+		t.Fatalf("expected port range to be kept, got '%s'", string(data))
+	}
+
+	def, err = userconfig.ParseServiceDefinition(data)
+	if err != nil {
+		t.Fatalf("ParseServiceDefinition of marshalled definition failed: %v", err)
+	}
+	if n := len(def.Components["component1"].Ports); n != 1002 {
+		t.Fatalf("expected 1002 ports, got %d", n)
+	}
+}
+
+func TestMarshalV2ConsecutivePorts(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"api": {
+				"image": "busybox:1.0",
+				"ports": [ "80/tcp", "81/tcp" ],
+				"volumes": [ { "path": "/data", "size": "5 GB" } ]
+			}
+		}
+	}`)
+
+	def, err := userconfig.ParseServiceDefinition(b)
+	if err != nil {
+		t.Fatalf("ParseServiceDefinition failed: %v", err)
+	}
+	data, err := json.Marshal(def)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"ports":["80/tcp","81/tcp"]`) {
+		t.Fatalf("expected ports to be kept as given, got '%s'", string(data))
+	}
+
+	// The generated name must not change
+	name, err := def.Name()
+	if err != nil {
+		t.Fatalf("Name failed: %v", err)
+	}
+	if name != "07586d96" {
+		t.Fatalf("expected name '07586d96', got '%s'", name)
+	}
+}
+
+func TestPortProtocolMatching(t *testing.T) {
+	udp := generictypes.MustParseDockerPort("53/udp")
+	tcp := generictypes.MustParseDockerPort("53/tcp")
+	list := []struct {
+		Name   string
+		Modify func(def *userconfig.ServiceDefinition)
+		Valid  bool
+	}{
+		{"link to udp port", func(def *userconfig.ServiceDefinition) {
+			def.Components["component/b"].Ports = userconfig.PortDefinitions{udp}
+			def.Components["component/a"].Links = userconfig.LinkDefinitions{{Component: "component/b", TargetPort: udp}}
+		}, true},
+		{"link with wrong protocol", func(def *userconfig.ServiceDefinition) {
+			def.Components["component/b"].Ports = userconfig.PortDefinitions{udp}
+			def.Components["component/a"].Links = userconfig.LinkDefinitions{{Component: "component/b", TargetPort: tcp}}
+		}, false},
+		{"expose udp port", func(def *userconfig.ServiceDefinition) {
+			def.Components["component/a"].Ports = userconfig.PortDefinitions{udp}
+			def.Components["component/a"].Expose = userconfig.ExposeDefinitions{{Port: udp}}
+		}, true},
+		{"expose with wrong protocol", func(def *userconfig.ServiceDefinition) {
+			def.Components["component/a"].Ports = userconfig.PortDefinitions{udp}
+			def.Components["component/a"].Expose = userconfig.ExposeDefinitions{{Port: tcp}}
+		}, false},
+		{"expose with mismatching target port", func(def *userconfig.ServiceDefinition) {
+			def.Components["component/a"].Ports = userconfig.PortDefinitions{udp}
+			def.Components["component/a"].Expose = userconfig.ExposeDefinitions{{Port: generictypes.MustParseDockerPort("5353/tcp"), TargetPort: udp}}
+		}, false},
+		{"domain on udp port", func(def *userconfig.ServiceDefinition) {
+			def.Components["component/a"].Ports = userconfig.PortDefinitions{udp}
+			def.Components["component/a"].Domains = userconfig.V2DomainDefinitions{"dns.com": userconfig.PortDefinitions{udp}}
+		}, false},
+		{"tcp health check on udp port", func(def *userconfig.ServiceDefinition) {
+			def.Components["component/a"].Ports = userconfig.PortDefinitions{udp}
+			def.Components["component/a"].HealthCheck = &userconfig.HealthCheckDefinition{TCP: &userconfig.TCPHealthCheck{Port: udp}}
+		}, false},
+	}
+
+	for _, test := range list {
+		def := ExampleDefinition()
+		test.Modify(&def)
+
+		valCtx := NewValidationContext()
+		valCtx.Protocols = []string{generictypes.ProtocolTCP, generictypes.ProtocolUDP}
+		if err := def.SetDefaults(valCtx); err != nil {
+			t.Fatalf("%s: SetDefaults failed: %v", test.Name, err)
+		}
+
+		err := def.Validate(valCtx)
+		if test.Valid && err != nil {
+			t.Fatalf("%s: considered invalid because %v", test.Name, err)
+		}
+		if !test.Valid && err == nil {
+			t.Fatalf("%s: considered valid", test.Name)
+		}
+	}
+}
+
+func TestPortsValidationMissingProtocols(t *testing.T) {
+	pds := userconfig.PortDefinitions{generictypes.MustParseDockerPort("80/tcp")}
+
+	err := pds.Validate(&userconfig.ValidationContext{})
+	if !userconfig.IsMissingValidationContext(err) {
+		t.Fatalf("expected MissingValidationContextError, got %#v", err)
+	}
+	if _, ok := userconfig.DiagnosticOf(err); !ok {
+		t.Fatalf("expected diagnostic, got %#v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package userconfig
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type RestartPolicy string
+
+const (
+	RestartAlways    RestartPolicy = "always"
+	RestartOnFailure RestartPolicy = "on-failure"
+	RestartNever     RestartPolicy = "never"
+)
+
+// Validate checks that the given enum is a valid value.
+func (rp RestartPolicy) Validate() error {
+	switch rp {
+	case RestartAlways, RestartOnFailure, RestartNever:
+	default:
+		return maskf(InvalidRestartPolicyError, "unknown value for restart policy: '%s'", rp)
+	}
+	return nil
+}
+
+// RestartDefinition describes what happens when the container of a component
+// exits.
+type RestartDefinition struct {
+	Policy RestartPolicy `json:"policy" description:"When to restart the component. Can be always, on-failure or never"`
+
+	// Maximum number of restarts after a failure. Only used by the on-failure
+	// policy. Zero means unlimited.
+	MaxRetries int `json:"max-retries,omitempty" description:"Maximum number of restarts for the on-failure policy. If empty, the component is restarted without limit"`
+}
+
+// String returns the marshalled string represantion of its own incarnation.
+// We use it to compare two RestartDefinitions when creating a diff. See
+// diff.go
+func (rd *RestartDefinition) String() string {
+	if rd == nil {
+		return ""
+	}
+
+	raw, err := json.Marshal(rd)
+	if err != nil {
+		panic(fmt.Sprintf("%#v\n", mask(err)))
+	}
+
+	return string(raw)
+}
+
+// Equals returns true if both definitions describe the same restart behavior.
+func (rd *RestartDefinition) Equals(other *RestartDefinition) bool {
+	if rd == nil || other == nil {
+		return rd == other
+	}
+
+	return rd.Policy == other.Policy && rd.MaxRetries == other.MaxRetries
+}
+
+func (rd *RestartDefinition) validate() error {
+	if err := rd.Policy.Validate(); err != nil {
+		return mask(err)
+	}
+
+	if rd.MaxRetries < 0 {
+		return maskf(InvalidRestartPolicyError, "restart max-retries '%d' cannot be negative", rd.MaxRetries)
+	}
+
+	if rd.MaxRetries != 0 && rd.Policy != RestartOnFailure {
+		return maskf(InvalidRestartPolicyError, "restart max-retries can only be used with policy '%s'", RestartOnFailure)
+	}
+
+	return nil
+}
+
+// stopSignals contains all signals that can be used to stop a container.
+var stopSignals = []string{
+	"SIGABRT",
+	"SIGHUP",
+	"SIGINT",
+	"SIGKILL",
+	"SIGQUIT",
+	"SIGTERM",
+	"SIGUSR1",
+	"SIGUSR2",
+	"SIGWINCH",
+}
+
+// validateStop checks the stop-signal and stop-timeout of this component.
+func (nd *ComponentDefinition) validateStop(valCtx *ValidationContext) error {
+	if nd.StopSignal != "" {
+		known := false
+		for _, s := range stopSignals {
+			if nd.StopSignal == s {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return newDiagnostic(InvalidStopSignalError, "unknown stop-signal '%s'", nd.StopSignal).withField("stop-signal").withValue(nd.StopSignal)
+		}
+	}
+
+	if nd.StopTimeout.IsEmpty() {
+		return nil
+	}
+
+	timeout, err := nd.StopTimeout.Duration()
+	if err != nil {
+		return newDiagnostic(InvalidStopTimeoutError, "invalid stop-timeout '%s'", nd.StopTimeout).withField("stop-timeout").withValue(nd.StopTimeout)
+	}
+
+	if valCtx == nil || valCtx.MaxStopTimeout.IsEmpty() {
+		return nil
+	}
+
+	max, err := valCtx.MaxStopTimeout.Duration()
+	if err != nil {
+		panic(fmt.Sprintf("Invalid valCtx.MaxStopTimeout: %#v", err))
+	}
+	if timeout > max {
+		return newDiagnostic(InvalidStopTimeoutError, "stop-timeout must be below %s", valCtx.MaxStopTimeout.String()).withField("stop-timeout").withValue(nd.StopTimeout)
+	}
+
+	return nil
+}
+
+func (nd *ComponentDefinition) setRestartDefaults(valCtx *ValidationContext) {
+	if nd.Restart == nil && valCtx.RestartPolicy != "" {
+		nd.Restart = &RestartDefinition{
+			Policy:     valCtx.RestartPolicy,
+			MaxRetries: valCtx.RestartMaxRetries,
+		}
+	}
+
+	if nd.StopSignal == "" {
+		nd.StopSignal = valCtx.StopSignal
+	}
+
+	if nd.StopTimeout.IsEmpty() {
+		nd.StopTimeout = valCtx.StopTimeout
+	}
+}
+
+func (nd *ComponentDefinition) hideRestartDefaults(valCtx *ValidationContext) {
+	if nd.Restart != nil && nd.Restart.Policy == valCtx.RestartPolicy && nd.Restart.MaxRetries == valCtx.RestartMaxRetries {
+		nd.Restart = nil
+	}
+
+	if nd.StopSignal == valCtx.StopSignal {
+		nd.StopSignal = ""
+	}
+
+	if nd.StopTimeout.Equals(valCtx.StopTimeout) {
+		nd.StopTimeout = ""
+	}
+}
+
+// validateRestartPolicyInPods checks that all restart policies and stop
+// timeouts within a pod are either not set or the same, since all components
+// of a pod are restarted and stopped together. Init components run to
+// completion before the others, so their restart policy may differ.
+func (nds *ComponentDefinitions) validateRestartPolicyInPods() error {
+	for componentName, componentDef := range *nds {
+		if !componentDef.IsPodRoot() {
+			continue
+		}
+
+		podComponents, err := nds.PodComponents(componentName)
+		if err != nil {
+			return mask(err)
+		}
+
+		var restart *RestartDefinition
+		var stopTimeout Duration
+		for _, name := range orderedComponentKeys(podComponents) {
+			c := podComponents[ComponentName(name)]
+			if c.Restart != nil && !c.IsInit() {
+				if restart != nil && !restart.Equals(c.Restart) {
+					return newDiagnostic(InvalidRestartPolicyError, "different restart policies in pod under '%s'", componentName.String()).withComponent(ComponentName(name)).withField("restart").withValue(c.Restart)
+				}
+				restart = c.Restart
+			}
+
+			if !c.StopTimeout.IsEmpty() {
+				if !stopTimeout.IsEmpty() && !stopTimeout.Equals(c.StopTimeout) {
+					return newDiagnostic(InvalidStopTimeoutError, "different stop-timeouts in pod under '%s'", componentName.String()).withComponent(ComponentName(name)).withField("stop-timeout").withValue(c.StopTimeout)
+				}
+				stopTimeout = c.StopTimeout
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/giantswarm/user-config"
+)
+
+func TestParseRestart(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"worker": {
+				"image": "registry/namespace/repository:version",
+				"restart": { "policy": "on-failure", "max-retries": 5 },
+				"stop-signal": "SIGINT",
+				"stop-timeout": "1m"
+			}
+		}
+	}`)
+
+	var serviceDef userconfig.ServiceDefinition
+	if err := json.Unmarshal(b, &serviceDef); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	c := serviceDef.Components["worker"]
+	if c.Restart == nil || c.Restart.Policy != userconfig.RestartOnFailure || c.Restart.MaxRetries != 5 {
+		t.Fatalf("invalid restart policy: %s", c.Restart.String())
+	}
+	if c.StopSignal != "SIGINT" || c.StopTimeout != "1m" {
+		t.Fatalf("invalid stop settings: %s, %s", c.StopSignal, c.StopTimeout)
+	}
+
+	if err := serviceDef.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+}
+
+func TestRestartValidation(t *testing.T) {
+	tests := []struct {
+		Restart     *userconfig.RestartDefinition
+		StopSignal  string
+		StopTimeout userconfig.Duration
+		Check       func(err error) bool
+	}{
+		// Valid ones
+		{&userconfig.RestartDefinition{Policy: userconfig.RestartAlways}, "", "", nil},
+		{&userconfig.RestartDefinition{Policy: userconfig.RestartOnFailure, MaxRetries: 3}, "SIGTERM", "30s", nil},
+		{&userconfig.RestartDefinition{Policy: userconfig.RestartNever}, "SIGKILL", "5m", nil},
+
+		// Invalid ones
+		{&userconfig.RestartDefinition{}, "", "", userconfig.IsInvalidRestartPolicy},
+		{&userconfig.RestartDefinition{Policy: "sometimes"}, "", "", userconfig.IsInvalidRestartPolicy},
+		{&userconfig.RestartDefinition{Policy: userconfig.RestartOnFailure, MaxRetries: -1}, "", "", userconfig.IsInvalidRestartPolicy},
+		{&userconfig.RestartDefinition{Policy: userconfig.RestartAlways, MaxRetries: 3}, "", "", userconfig.IsInvalidRestartPolicy},
+		{nil, "TERM", "", userconfig.IsInvalidStopSignal},
+		{nil, "SIGFOO", "", userconfig.IsInvalidStopSignal},
+		{nil, "", "soon", userconfig.IsInvalidStopTimeout},
+		{nil, "", "10m", userconfig.IsInvalidStopTimeout},
+	}
+
+	for i, test := range tests {
+		def := ExampleDefinition()
+		def.Components["component/a"].Restart = test.Restart
+		def.Components["component/a"].StopSignal = test.StopSignal
+		def.Components["component/a"].StopTimeout = test.StopTimeout
+
+		valCtx := NewValidationContext()
+		valCtx.MaxStopTimeout = "5m"
+
+		err := def.Validate(valCtx)
+		if test.Check == nil && err != nil {
+			t.Fatalf("test %d: expected definition to be valid, got error: %#v", i, err)
+		}
+		if test.Check != nil && !test.Check(err) {
+			t.Fatalf("test %d: unexpected error: %#v", i, err)
+		}
+	}
+}
+
+func TestRestartDefaults(t *testing.T) {
+	a := ExampleDefinition()
+	a.Components["component/a"].StopTimeout = "20s"
+
+	valCtx := NewValidationContext()
+	valCtx.RestartPolicy = userconfig.RestartAlways
+	valCtx.StopSignal = "SIGTERM"
+	valCtx.StopTimeout = "10s"
+
+	if err := a.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+
+	c := a.Components["component/a"]
+	if c.Restart == nil || c.Restart.Policy != userconfig.RestartAlways {
+		t.Fatalf("expected default restart policy, got: %s", c.Restart.String())
+	}
+	if c.StopSignal != "SIGTERM" {
+		t.Fatalf("expected default stop-signal, got '%s'", c.StopSignal)
+	}
+	if c.StopTimeout != "20s" {
+		t.Fatalf("expected explicit stop-timeout to be kept, got '%s'", c.StopTimeout)
+	}
+
+	if err := a.Validate(valCtx); err != nil {
+		t.Fatalf("validating service failed: %#v", err)
+	}
+
+	b, err := a.HideDefaults(valCtx)
+	if err != nil {
+		t.Fatalf("hiding defaults failed: %#v", err)
+	}
+
+	c = b.Components["component/a"]
+	if c.Restart != nil || c.StopSignal != "" {
+		t.Fatalf("defaults not hidden: %s, %s", c.Restart.String(), c.StopSignal)
+	}
+	if c.StopTimeout != "20s" {
+		t.Fatalf("expected explicit stop-timeout to be kept, got '%s'", c.StopTimeout)
+	}
+}
+
+func TestRestartPolicyInPods(t *testing.T) {
+	service := testService()
+	service.Components["root"] = setPod(&userconfig.ComponentDefinition{}, userconfig.PodChildren)
+	service.Components["root/a"] = testComponent()
+	service.Components["root/a"].Restart = &userconfig.RestartDefinition{Policy: userconfig.RestartOnFailure}
+	service.Components["root/b"] = testComponent()
+
+	// An explicit setting is shared within the pod
+	valCtx := NewValidationContext()
+	valCtx.RestartPolicy = userconfig.RestartAlways
+	if err := service.SetDefaults(valCtx); err != nil {
+		t.Fatalf("setting defaults failed: %#v", err)
+	}
+	if p := service.Components["root/b"].Restart; p == nil || p.Policy != userconfig.RestartOnFailure {
+		t.Fatalf("expected restart policy to be shared in pod, got: %s", p.String())
+	}
+	if err := service.Validate(valCtx); err != nil {
+		t.Fatalf("validating service failed: %#v", err)
+	}
+
+	service.Components["root/b"].Restart = &userconfig.RestartDefinition{Policy: userconfig.RestartNever}
+	if err := service.Validate(valCtx); !userconfig.IsInvalidRestartPolicy(err) {
+		t.Fatalf("expected error to be InvalidRestartPolicyError, got: %#v", err)
+	}
+
+	service.Components["root/b"].Restart = nil
+	service.Components["root/a"].StopTimeout = "10s"
+	service.Components["root/b"].StopTimeout = "20s"
+	if err := service.Validate(valCtx); !userconfig.IsInvalidStopTimeout(err) {
+		t.Fatalf("expected error to be InvalidStopTimeoutError, got: %#v", err)
+	}
+}
+
+func TestRestartDiff(t *testing.T) {
+	oldDef := ExampleDefinition()
+	newDef := ExampleDefinition()
+	newDef.Components["component/a"].Restart = &userconfig.RestartDefinition{Policy: userconfig.RestartNever}
+	newDef.Components["component/a"].StopSignal = "SIGINT"
+	newDef.Components["component/a"].StopTimeout = "1m"
+
+	diffInfos := userconfig.ServiceDiff(oldDef, newDef)
+	for _, dt := range []userconfig.DiffType{
+		userconfig.DiffTypeComponentRestartUpdated,
+		userconfig.DiffTypeComponentStopSignalUpdated,
+		userconfig.DiffTypeComponentStopTimeoutUpdated,
+	} {
+		if len(userconfig.DiffInfosByType(diffInfos, dt)) != 1 {
+			t.Fatalf("expected one diff of type '%s': %#v", dt, diffInfos)
+		}
+	}
+}
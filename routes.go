@@ -0,0 +1,151 @@
+package userconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/generic-types-go"
+)
+
+// rootPath is the path a domain is routed on when no paths are given.
+const rootPath = "/"
+
+// routedPaths returns the normalized path prefixes of these settings.
+func (ds DomainSettings) routedPaths() []string {
+	if len(ds.Paths) == 0 {
+		return []string{rootPath}
+	}
+
+	paths := []string{}
+	for _, p := range ds.Paths {
+		paths = append(paths, normalizeRoutePath(p))
+	}
+	return paths
+}
+
+func (ds DomainSettings) validatePaths(domain generictypes.Domain) error {
+	seen := map[string]string{}
+	for _, p := range ds.Paths {
+		if !strings.HasPrefix(p, "/") {
+			return maskf(InvalidDomainDefinitionError, "path '%s' of domain '%s' must start with '/'", p, domain)
+		}
+		if strings.ContainsAny(p, "?#*") {
+			return maskf(InvalidDomainDefinitionError, "path '%s' of domain '%s' must be a plain path prefix", p, domain)
+		}
+		if strings.Contains(p, "//") {
+			return maskf(InvalidDomainDefinitionError, "path '%s' of domain '%s' must not contain empty segments", p, domain)
+		}
+
+		normalized := normalizeRoutePath(p)
+		if other, ok := seen[normalized]; ok {
+			return maskf(InvalidDomainDefinitionError, "paths '%s' and '%s' of domain '%s' are the same", other, p, domain)
+		}
+		seen[normalized] = p
+	}
+
+	return nil
+}
+
+// normalizeRoutePath removes a trailing slash, so "/api/" and "/api" are
+// treated as the same route.
+func normalizeRoutePath(p string) string {
+	if p == rootPath {
+		return p
+	}
+	return strings.TrimSuffix(p, "/")
+}
+
+// routeHasPrefix returns true if the given path is routed by the given
+// prefix. Prefixes match whole segments only, so "/api" does not route
+// "/apis".
+func routeHasPrefix(p, prefix string) bool {
+	if prefix == rootPath {
+		return true
+	}
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
+// route is a single domain + port + path prefix claimed by a component.
+type route struct {
+	Domain    generictypes.Domain
+	Port      generictypes.DockerPort
+	Path      string
+	Component ComponentName
+}
+
+// routes returns all routes of all components, ordered by domain, port, path
+// and component name. Domains are normalized, so routes of domains that only
+// differ in case are the same.
+func (nds ComponentDefinitions) routes() []route {
+	list := []route{}
+	for componentName, def := range nds {
+		for domain, ports := range def.Domains {
+			for _, port := range ports {
+				for _, p := range def.DomainSettings[domain].routedPaths() {
+					list = append(list, route{Domain: normalizeDomainName(domain), Port: port, Path: p, Component: componentName})
+				}
+			}
+		}
+	}
+	sort.Sort(routesByName(list))
+	return list
+}
+
+// validateUniqueDomains checks that no two components of the service bind the
+// same domain, port and path.
+func (nds ComponentDefinitions) validateUniqueDomains() error {
+	claims := map[string]route{}
+	for _, r := range nds.routes() {
+		key := r.Domain.String() + " " + r.Port.String() + " " + r.Path
+		if other, ok := claims[key]; ok && other.Component != r.Component {
+			return newDiagnostic(DuplicateDomainError, "path '%s' of domain '%s' on port '%s' is bound by component '%s' and '%s'", r.Path, r.Domain, r.Port, other.Component, r.Component).withComponent(r.Component).withField(fmt.Sprintf("domains[%q].paths", r.Domain)).withValue(r.Path)
+		}
+		claims[key] = r
+	}
+
+	return nil
+}
+
+// routeWarnings returns a warning for each path prefix of a domain that
+// is nested in a prefix of the same domain claimed by another component.
+// The root path is not reported, since it is the common catch-all route.
+func (nds ComponentDefinitions) routeWarnings() Warnings {
+	warnings := Warnings{}
+	list := nds.routes()
+	for _, outer := range list {
+		if outer.Path == rootPath {
+			continue
+		}
+		for _, inner := range list {
+			if inner.Domain != outer.Domain || !inner.Port.Equals(outer.Port) || inner.Component == outer.Component || inner.Path == outer.Path {
+				continue
+			}
+			if routeHasPrefix(inner.Path, outer.Path) {
+				warnings = append(warnings, Warning{
+					Component: inner.Component,
+					Message:   fmt.Sprintf("path '%s' of domain '%s' overlaps with path '%s' of component '%s'", inner.Path, inner.Domain, outer.Path, outer.Component),
+				})
+			}
+		}
+	}
+
+	return warnings
+}
+
+type routesByName []route
+
+func (r routesByName) Len() int      { return len(r) }
+func (r routesByName) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r routesByName) Less(i, j int) bool {
+	if r[i].Domain != r[j].Domain {
+		return r[i].Domain < r[j].Domain
+	}
+	if !r[i].Port.Equals(r[j].Port) {
+		return r[i].Port.String() < r[j].Port.String()
+	}
+	if r[i].Path != r[j].Path {
+		return r[i].Path < r[j].Path
+	}
+	return r[i].Component < r[j].Component
+}
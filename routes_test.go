@@ -0,0 +1,127 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/generic-types-go"
+	"github.com/giantswarm/user-config"
+)
+
+func routedDefinition(pathsA, pathsB []string) userconfig.ServiceDefinition {
+	def := ExampleDefinition()
+	port := userconfig.PortDefinitions{generictypes.MustParseDockerPort("80/tcp")}
+	def.Components["component/a"].Domains = userconfig.V2DomainDefinitions{"shared.com": port}
+	def.Components["component/a"].DomainSettings = userconfig.V2DomainSettings{"shared.com": {Paths: pathsA}}
+	def.Components["component/b"].Domains = userconfig.V2DomainDefinitions{"shared.com": port}
+	def.Components["component/b"].DomainSettings = userconfig.V2DomainSettings{"shared.com": {Paths: pathsB}}
+	return def
+}
+
+func TestDomainRoutesValidation(t *testing.T) {
+	list := []struct {
+		PathsA []string
+		PathsB []string
+		Valid  bool
+	}{
+		{[]string{"/api"}, nil, true},
+		{[]string{"/api", "/admin/"}, []string{"/"}, true},
+		{[]string{"/api"}, []string{"/web"}, true},
+		{[]string{"/api/v1"}, []string{"/api"}, true},
+		{nil, nil, false},
+		{[]string{"/"}, nil, false},
+		{[]string{"/api"}, []string{"/api/"}, false},
+		{[]string{"api"}, nil, false},
+		{[]string{"/api?x=1"}, nil, false},
+		{[]string{"/api//v1"}, nil, false},
+		{[]string{"/api", "/api/"}, nil, false},
+	}
+
+	for i, test := range list {
+		def := routedDefinition(test.PathsA, test.PathsB)
+		err := def.Validate(nil)
+		if test.Valid && err != nil {
+			t.Fatalf("Test %d: valid routes considered invalid because %v", i, err)
+		}
+		if !test.Valid {
+			if err == nil {
+				t.Fatalf("Test %d: invalid routes considered valid", i)
+			}
+			if !userconfig.IsInvalidDomainDefinition(err) {
+				t.Fatalf("Test %d: expected InvalidDomainDefinitionError, got %v", i, err)
+			}
+		}
+	}
+}
+
+func TestDomainRoutesWarnings(t *testing.T) {
+	list := []struct {
+		PathsA   []string
+		PathsB   []string
+		Warnings int
+	}{
+		{[]string{"/api"}, nil, 0},
+		{[]string{"/api"}, []string{"/apis"}, 0},
+		{[]string{"/api/v1"}, []string{"/api"}, 1},
+		{[]string{"/api/v1", "/api/v2"}, []string{"/api"}, 2},
+		{[]string{"/api", "/api/v1"}, []string{"/web"}, 0},
+	}
+
+	for i, test := range list {
+		def := routedDefinition(test.PathsA, test.PathsB)
+		if err := def.Validate(nil); err != nil {
+			t.Fatalf("Test %d: validation failed: %v", i, err)
+		}
+		warnings := def.Warnings()
+		if len(warnings) != test.Warnings {
+			t.Fatalf("Test %d: expected %d warnings, got %v", i, test.Warnings, warnings)
+		}
+	}
+
+	def := routedDefinition([]string{"/api/v1"}, []string{"/api"})
+	warnings := def.Warnings()
+	if warnings[0].Component != "component/a" {
+		t.Fatalf("expected warning for component/a, got %v", warnings[0])
+	}
+	if !strings.Contains(warnings[0].Message, "component/b") {
+		t.Fatalf("expected warning to mention component/b, got '%s'", warnings[0].Message)
+	}
+}
+
+func TestDomainRoutesFullService(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"api": {
+				"image": "busybox",
+				"ports": [ "8080/tcp" ],
+				"domains": {
+					"shared.com": { "port": "8080", "paths": [ "/api" ] }
+				}
+			},
+			"web": {
+				"image": "busybox",
+				"ports": [ "80/tcp" ],
+				"domains": {
+					"80": [ "shared.com" ]
+				}
+			}
+		}
+	}`)
+
+	def, err := userconfig.ParseServiceDefinition(b)
+	if err != nil {
+		t.Fatalf("ParseServiceDefinition failed: %v", err)
+	}
+	if err := def.Validate(nil); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	data, err := json.Marshal(def.Components["api"])
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"paths":["/api"]`) {
+		t.Fatalf("expected paths in '%s'", string(data))
+	}
+}
@@ -34,7 +34,7 @@ func (pl Placement) Validate() error {
 	switch pl {
 	case DefaultPlacement, OnePerMachinePlacement:
 	default:
-		return maskf(InvalidScalingConfigError, "unknown value for scale placement: '%s'", pl)
+		return newDiagnostic(InvalidScalingConfigError, "unknown value for scale placement: '%s'", pl).withField("placement").withValue(pl)
 	}
 	return nil
 }
@@ -47,6 +47,12 @@ type ScaleDefinition struct {
 	Max int `json:"max,omitempty" description:"Maximum number of instances to launch"`
 
 	Placement Placement `json:"placement,omitempty" description:"Placement strategy when scaling a component. Can be empty or one-per-machine"`
+
+	// Restrictions of the machines the instances can be placed on.
+	Constraints *PlacementConstraints `json:"constraints,omitempty" description:"Restrictions of the machines the instances can be placed on"`
+
+	// How to move the number of instances between min and max.
+	Autoscale *AutoscaleDefinition `json:"autoscale,omitempty" description:"Policy to automatically scale between min and max"`
 }
 
 func (sd *ScaleDefinition) String() string {
@@ -64,26 +70,52 @@ func (sd *ScaleDefinition) String() string {
 }
 
 func (sd *ScaleDefinition) validate(valCtx *ValidationContext) error {
+	if sd.Constraints != nil {
+		if err := sd.Constraints.validate(sd.Placement); err != nil {
+			return diagnosticInField(err, "constraints")
+		}
+	}
+
+	if sd.Autoscale != nil {
+		if err := sd.Autoscale.validate(); err != nil {
+			return diagnosticInField(err, "autoscale")
+		}
+	}
+
 	if valCtx == nil {
 		return nil
 	}
 
 	if sd.Min < valCtx.MinScaleSize {
-		return maskf(InvalidScalingConfigError, "scale min '%d' cannot be less than '%d'", sd.Min, valCtx.MinScaleSize)
+		return newDiagnostic(InvalidScalingConfigError, "scale min '%d' cannot be less than '%d'", sd.Min, valCtx.MinScaleSize).withField("min").withValue(sd.Min).withFix("set min to at least %d", valCtx.MinScaleSize)
 	}
 
 	if sd.Max > valCtx.MaxScaleSize {
-		return maskf(InvalidScalingConfigError, "scale max '%d' cannot be greater than '%d'", sd.Max, valCtx.MaxScaleSize)
+		return newDiagnostic(InvalidScalingConfigError, "scale max '%d' cannot be greater than '%d'", sd.Max, valCtx.MaxScaleSize).withField("max").withValue(sd.Max).withFix("set max to at most %d", valCtx.MaxScaleSize)
 	}
 
 	if sd.Min > sd.Max {
-		return maskf(InvalidScalingConfigError, "scale min '%d' cannot be greater than scale max '%d'", sd.Min, sd.Max)
+		return newDiagnostic(InvalidScalingConfigError, "scale min '%d' cannot be greater than scale max '%d'", sd.Min, sd.Max).withField("min").withValue(sd.Min)
 	}
 
 	if err := sd.Placement.Validate(); err != nil {
 		return mask(err)
 	}
 
+	if sd.Autoscale != nil {
+		// Unset bounds are filled in by the defaults later on
+		min, max := sd.Min, sd.Max
+		if min == 0 {
+			min = valCtx.MinScaleSize
+		}
+		if max == 0 {
+			max = valCtx.MaxScaleSize
+		}
+		if err := sd.Autoscale.validateBounds(min, max); err != nil {
+			return mask(err)
+		}
+	}
+
 	return nil
 }
 
@@ -104,6 +136,12 @@ func (sd *ScaleDefinition) setDefaults(valCtx *ValidationContext) {
 			sd.Placement = DefaultPlacement
 		}
 	}
+
+	// A fixed number of instances leaves nothing to scale automatically
+	if sd.Autoscale == nil && valCtx.Autoscale != nil && sd.Max > sd.Min {
+		autoscale := *valCtx.Autoscale
+		sd.Autoscale = &autoscale
+	}
 }
 
 func (sd *ScaleDefinition) hideDefaults(valCtx *ValidationContext) *ScaleDefinition {
@@ -112,7 +150,11 @@ func (sd *ScaleDefinition) hideDefaults(valCtx *ValidationContext) *ScaleDefinit
 	if valCtx.Placement != "" {
 		defaultPlacement = valCtx.Placement
 	}
-	if sd.Min == valCtx.MinScaleSize && sd.Max == valCtx.MaxScaleSize && sd.Placement == defaultPlacement {
+	if sd.Max > sd.Min && sd.Autoscale.Equals(valCtx.Autoscale) {
+		sd.Autoscale = nil
+	}
+
+	if sd.Min == valCtx.MinScaleSize && sd.Max == valCtx.MaxScaleSize && sd.Placement == defaultPlacement && sd.Constraints == nil && sd.Autoscale == nil {
 		return nil
 	}
 
@@ -144,12 +186,15 @@ func (nds *ComponentDefinitions) validateScalingPolicyInPods() error {
 			return mask(err)
 		}
 		list := []ScaleDefinition{}
-		for _, c := range podComponents {
+		names := []ComponentName{}
+		for _, name := range orderedComponentKeys(podComponents) {
+			c := podComponents[ComponentName(name)]
 			if c.Scale == nil {
 				// No scaling policy set
 				continue
 			}
 			list = append(list, *c.Scale)
+			names = append(names, ComponentName(name))
 		}
 
 		// Check each list for errors
@@ -159,19 +204,31 @@ func (nds *ComponentDefinitions) validateScalingPolicyInPods() error {
 				if p1.Min != 0 && p2.Min != 0 {
 					// Both minimums specified, must be the same
 					if p1.Min != p2.Min {
-						return maskf(InvalidScalingConfigError, "different minimum scaling policies in pod under '%s'", componentName.String())
+						return newDiagnostic(InvalidScalingConfigError, "different minimum scaling policies in pod under '%s'", componentName.String()).withComponent(names[j]).withField("scale.min").withValue(p2.Min)
 					}
 				}
 				if p1.Max != 0 && p2.Max != 0 {
 					// Both maximums specified, must be the same
 					if p1.Max != p2.Max {
-						return maskf(InvalidScalingConfigError, "different maximum scaling policies in pod under '%s'", componentName.String())
+						return newDiagnostic(InvalidScalingConfigError, "different maximum scaling policies in pod under '%s'", componentName.String()).withComponent(names[j]).withField("scale.max").withValue(p2.Max)
 					}
 				}
 
 				if p1.Placement != "" && p2.Placement != "" {
 					if p1.Placement != p2.Placement {
-						return maskf(InvalidScalingConfigError, "different scaling placement policies in pod under '%s'", componentName.String())
+						return newDiagnostic(InvalidScalingConfigError, "different scaling placement policies in pod under '%s'", componentName.String()).withComponent(names[j]).withField("scale.placement").withValue(p2.Placement)
+					}
+				}
+
+				if p1.Constraints.hasMachineConstraints() && p2.Constraints.hasMachineConstraints() {
+					if !p1.Constraints.machineConstraints().Equals(p2.Constraints.machineConstraints()) {
+						return newDiagnostic(InvalidScalingConfigError, "different placement constraints in pod under '%s'", componentName.String()).withComponent(names[j]).withField("scale.constraints").withValue(p2.Constraints)
+					}
+				}
+
+				if p1.Autoscale != nil && p2.Autoscale != nil {
+					if !p1.Autoscale.Equals(p2.Autoscale) {
+						return newDiagnostic(InvalidScalingConfigError, "different autoscale policies in pod under '%s'", componentName.String()).withComponent(names[j]).withField("scale.autoscale").withValue(p2.Autoscale)
 					}
 				}
 			}
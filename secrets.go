@@ -0,0 +1,129 @@
+package userconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+)
+
+var (
+	secretNameRegExp = regexp.MustCompile("^[a-zA-Z0-9]{1}[a-z0-9A-Z_.-]{0,99}$")
+	envNameRegExp    = regexp.MustCompile("^[a-zA-Z_]{1}[a-zA-Z0-9_]*$")
+)
+
+// SecretDefinition references a named secret and describes how it is handed to
+// the container. The value of the secret is never part of the definition.
+type SecretDefinition struct {
+	// Name of the secret to use.
+	Name string `json:"name" description:"Name of the secret to use"`
+
+	// Name of the environment variable that receives the secret.
+	Env string `json:"env,omitempty" description:"Name of the environment variable to expose the secret as"`
+
+	// Path of the file (inside the container) that receives the secret.
+	Path string `json:"path,omitempty" description:"Path of the file (inside the container) to expose the secret as"`
+}
+
+type SecretDefinitions []SecretDefinition
+
+// String returns the string represantion of the current incarnation.
+func (sd SecretDefinition) String() string {
+	// A string map is reliable enough for our case, as the JSON implementation
+	// takes care of the order of the provided fields. See
+	// http://play.golang.org/p/U8nDgdga2X
+	m := map[string]string{
+		"name": sd.Name,
+		"env":  sd.Env,
+		"path": sd.Path,
+	}
+
+	raw, err := json.Marshal(m)
+	if err != nil {
+		panic(fmt.Sprintf("%#v\n", mask(err)))
+	}
+
+	return string(raw)
+}
+
+func (sd SecretDefinition) validate() error {
+	if sd.Name == "" {
+		return maskf(InvalidSecretDefinitionError, "secret name must not be empty")
+	}
+	if !secretNameRegExp.MatchString(sd.Name) {
+		return maskf(InvalidSecretDefinitionError, "secret name '%s' must match regexp: %s", sd.Name, secretNameRegExp)
+	}
+
+	if sd.Env == "" && sd.Path == "" {
+		return maskf(InvalidSecretDefinitionError, "env or path must be set for secret '%s'", sd.Name)
+	}
+	if sd.Env != "" && sd.Path != "" {
+		return maskf(InvalidSecretDefinitionError, "env and path cannot be set both for secret '%s'", sd.Name)
+	}
+
+	if sd.Env != "" && !envNameRegExp.MatchString(sd.Env) {
+		return maskf(InvalidSecretDefinitionError, "env '%s' of secret '%s' must match regexp: %s", sd.Env, sd.Name, envNameRegExp)
+	}
+	if sd.Path != "" && !path.IsAbs(sd.Path) {
+		return maskf(InvalidSecretDefinitionError, "path '%s' of secret '%s' must be absolute", sd.Path, sd.Name)
+	}
+
+	return nil
+}
+
+// validate checks all secrets for invalid entries, duplicate targets and for
+// environment variables that are already set by the given env.
+func (sds SecretDefinitions) validate(env EnvList) error {
+	envKeys := map[string]string{}
+	for _, key := range env.Keys() {
+		envKeys[key] = key
+	}
+
+	targets := map[string]string{}
+	for i, sd := range sds {
+		field := fmt.Sprintf("[%d]", i)
+		if err := sd.validate(); err != nil {
+			return mask(diagnosticInField(err, field))
+		}
+
+		if sd.Env != "" {
+			if _, ok := envKeys[sd.Env]; ok {
+				return newDiagnostic(InvalidSecretDefinitionError, "env '%s' of secret '%s' is already set in 'env'", sd.Env, sd.Name).withField(field+".env").withValue(sd.Env).withFix("remove '%s' from env", sd.Env)
+			}
+			if _, ok := targets["env:"+sd.Env]; ok {
+				return newDiagnostic(InvalidSecretDefinitionError, "env '%s' is used by multiple secrets", sd.Env).withField(field + ".env").withValue(sd.Env)
+			}
+			targets["env:"+sd.Env] = sd.Name
+		}
+
+		if sd.Path != "" {
+			p := normalizeFolder(sd.Path)
+			if _, ok := targets["path:"+p]; ok {
+				return newDiagnostic(InvalidSecretDefinitionError, "path '%s' is used by multiple secrets", sd.Path).withField(field + ".path").withValue(sd.Path)
+			}
+			targets["path:"+p] = sd.Name
+		}
+	}
+
+	return nil
+}
+
+// String returns the marshalled and ordered string represantion of its own
+// incarnation. It is important to have the string represantion ordered, since
+// we use it to compare two SecretDefinitions when creating a diff. See diff.go
+func (sds SecretDefinitions) String() string {
+	list := []string{}
+
+	for _, sd := range sds {
+		list = append(list, sd.String())
+	}
+	sort.Strings(list)
+
+	raw, err := json.Marshal(list)
+	if err != nil {
+		panic(fmt.Sprintf("%#v\n", mask(err)))
+	}
+
+	return string(raw)
+}
@@ -0,0 +1,193 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/user-config"
+)
+
+func TestParseSecrets(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"api": {
+				"image": "registry/namespace/repository:version",
+				"env": { "DB_USER": "api" },
+				"secrets": [
+					{ "name": "db-password", "env": "DB_PASSWORD" },
+					{ "name": "tls-key", "path": "/etc/tls/key.pem" }
+				]
+			}
+		}
+	}`)
+
+	var serviceDef userconfig.ServiceDefinition
+	if err := json.Unmarshal(b, &serviceDef); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	secrets := serviceDef.Components["api"].Secrets
+	if len(secrets) != 2 {
+		t.Fatalf("expected two secrets: %d given", len(secrets))
+	}
+	if secrets[0].Name != "db-password" || secrets[0].Env != "DB_PASSWORD" {
+		t.Fatalf("invalid secret: %s", secrets[0].String())
+	}
+	if secrets[1].Name != "tls-key" || secrets[1].Path != "/etc/tls/key.pem" {
+		t.Fatalf("invalid secret: %s", secrets[1].String())
+	}
+
+	if err := serviceDef.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+}
+
+func TestSecretsValidation(t *testing.T) {
+	tests := []struct {
+		Env     userconfig.EnvList
+		Volumes userconfig.VolumeDefinitions
+		Secrets userconfig.SecretDefinitions
+		Valid   bool
+	}{
+		// Valid ones
+		{
+			nil,
+			nil,
+			userconfig.SecretDefinitions{{Name: "db-password", Env: "DB_PASSWORD"}},
+			true,
+		},
+		{
+			userconfig.EnvList{"DB_USER=api"},
+			userconfig.VolumeDefinitions{{Path: "/data", Size: "5 GB"}},
+			userconfig.SecretDefinitions{{Name: "db-password", Env: "DB_PASSWORD"}, {Name: "key", Path: "/etc/key.pem"}},
+			true,
+		},
+		{
+			// Same secret exposed twice
+			nil,
+			nil,
+			userconfig.SecretDefinitions{{Name: "key", Env: "KEY"}, {Name: "key", Path: "/etc/key.pem"}},
+			true,
+		},
+
+		// Invalid ones
+		{
+			nil,
+			nil,
+			userconfig.SecretDefinitions{{Env: "DB_PASSWORD"}},
+			false,
+		},
+		{
+			nil,
+			nil,
+			userconfig.SecretDefinitions{{Name: "db password", Env: "DB_PASSWORD"}},
+			false,
+		},
+		{
+			nil,
+			nil,
+			userconfig.SecretDefinitions{{Name: "db-password"}},
+			false,
+		},
+		{
+			nil,
+			nil,
+			userconfig.SecretDefinitions{{Name: "db-password", Env: "DB_PASSWORD", Path: "/etc/password"}},
+			false,
+		},
+		{
+			nil,
+			nil,
+			userconfig.SecretDefinitions{{Name: "db-password", Env: "DB-PASSWORD"}},
+			false,
+		},
+		{
+			nil,
+			nil,
+			userconfig.SecretDefinitions{{Name: "key", Path: "etc/key.pem"}},
+			false,
+		},
+		{
+			// Clashes with env
+			userconfig.EnvList{"DB_PASSWORD=secret"},
+			nil,
+			userconfig.SecretDefinitions{{Name: "db-password", Env: "DB_PASSWORD"}},
+			false,
+		},
+		{
+			// Duplicate env
+			nil,
+			nil,
+			userconfig.SecretDefinitions{{Name: "a", Env: "PASSWORD"}, {Name: "b", Env: "PASSWORD"}},
+			false,
+		},
+		{
+			// Duplicate path
+			nil,
+			nil,
+			userconfig.SecretDefinitions{{Name: "a", Path: "/etc/key"}, {Name: "b", Path: "/etc/key/"}},
+			false,
+		},
+		{
+			// Clashes with volume
+			nil,
+			userconfig.VolumeDefinitions{{Path: "/data", Size: "5 GB"}},
+			userconfig.SecretDefinitions{{Name: "key", Path: "/data"}},
+			false,
+		},
+		{
+			// Inside volume
+			nil,
+			userconfig.VolumeDefinitions{{Path: "/data/", Size: "5 GB"}},
+			userconfig.SecretDefinitions{{Name: "key", Path: "/data/key.pem"}},
+			false,
+		},
+	}
+
+	for i, test := range tests {
+		def := ExampleDefinition()
+		def.Components["component/a"].Env = test.Env
+		def.Components["component/a"].Volumes = test.Volumes
+		def.Components["component/a"].Secrets = test.Secrets
+
+		err := def.Validate(nil)
+		if test.Valid && err != nil {
+			t.Fatalf("test %d: expected definition to be valid, got error: %#v", i, err)
+		}
+		if !test.Valid && !userconfig.IsInvalidSecretDefinition(err) {
+			t.Fatalf("test %d: expected error to be InvalidSecretDefinitionError, got: %#v", i, err)
+		}
+	}
+}
+
+func TestSecretsFromSharedVolumes(t *testing.T) {
+	service := testService()
+	service.Components["root"] = setPod(&userconfig.ComponentDefinition{}, userconfig.PodChildren)
+	service.Components["root/a"] = testComponent()
+	service.Components["root/a"].Volumes = userconfig.VolumeDefinitions{{Path: "/shared", Size: "1 GB"}}
+	service.Components["root/b"] = testComponent()
+	service.Components["root/b"].Volumes = userconfig.VolumeDefinitions{{VolumesFrom: "root/a"}}
+	service.Components["root/b"].Secrets = userconfig.SecretDefinitions{{Name: "key", Path: "/shared/key.pem"}}
+
+	if err := service.Validate(nil); !userconfig.IsInvalidSecretDefinition(err) {
+		t.Fatalf("expected error to be InvalidSecretDefinitionError, got: %#v", err)
+	}
+}
+
+func TestSecretsDiff(t *testing.T) {
+	oldDef := ExampleDefinition()
+	newDef := ExampleDefinition()
+	newDef.Components["component/a"].Secrets = userconfig.SecretDefinitions{{Name: "db-password", Env: "DB_PASSWORD"}}
+
+	diffInfos := userconfig.DiffInfosByType(userconfig.ServiceDiff(oldDef, newDef), userconfig.DiffTypeComponentSecretsUpdated)
+	if len(diffInfos) != 1 {
+		t.Fatalf("expected one diff: %#v", diffInfos)
+	}
+	if diffInfos[0].Old != "[]" {
+		t.Fatalf("unexpected old value: %s", diffInfos[0].Old)
+	}
+	if !strings.Contains(diffInfos[0].New, "db-password") || !strings.Contains(diffInfos[0].New, "DB_PASSWORD") {
+		t.Fatalf("expected new value to reference the secret, got: %s", diffInfos[0].New)
+	}
+}
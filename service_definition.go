@@ -31,22 +31,20 @@ func ParseServiceDefinition(b []byte) (ServiceDefinition, error) {
 	return serviceDef, nil
 }
 
+type serviceDefCopy ServiceDefinition
+
 func (sd *ServiceDefinition) UnmarshalJSON(data []byte) error {
-	// We fix the json buffer so CheckForUnknownFields doesn't complain about
+	// We fix the json buffer so decodeStrict doesn't complain about
 	// `Components` (with uper N).
 	data, err := FixJSONFieldNames(data)
 	if err != nil {
 		return err
 	}
 
-	if err := CheckForUnknownFields(data, sd); err != nil {
-		return err
-	}
-
-	// Just unmarshal the given bytes into the service def struct, since there
-	// were no errors.
+	// Decode strictly, so unknown fields are reported, also those of nested
+	// definitions.
 	var sdc serviceDefCopy
-	if err := json.Unmarshal(data, &sdc); err != nil {
+	if err := decodeStrict(data, &sdc); err != nil {
 		return mask(err)
 	}
 
@@ -57,6 +55,21 @@ func (sd *ServiceDefinition) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// CheckForUnknownFields returns an UnknownJSONFieldError if the given service
+// definition contains a field that is not known. ac is reset in that case.
+// UnmarshalJSON performs this check already.
+func CheckForUnknownFields(b []byte, ac *ServiceDefinition) error {
+	var sdc serviceDefCopy
+	if err := decodeStrict(b, &sdc); err != nil {
+		if IsUnknownJsonField(err) {
+			*ac = ServiceDefinition{}
+		}
+		return mask(err)
+	}
+
+	return nil
+}
+
 type ValidationContext struct {
 	Org       string
 	Protocols []string
@@ -64,6 +77,7 @@ type ValidationContext struct {
 	MinScaleSize int
 	MaxScaleSize int
 	Placement    Placement
+	Autoscale    *AutoscaleDefinition // Default autoscaling policy. If nil, components are not scaled automatically
 
 	MinVolumeSize VolumeSize
 	MaxVolumeSize VolumeSize
@@ -71,6 +85,47 @@ type ValidationContext struct {
 	EnableUserMemoryLimit bool // If false, the component definition MUST NOT have a memory-limit configured
 	MinMemoryLimit        ByteSize
 	MaxMemoryLimit        ByteSize
+	MaxPodMemoryRequest   ByteSize // If set, the sum of all memory-request values within a pod MUST NOT exceed this
+
+	EnableUserMemorySwap bool // If false, the component definition MUST NOT have a memory-swap configured
+	MinMemorySwap        ByteSize
+	MaxMemorySwap        ByteSize
+
+	EnableUserCPULimit bool // If false, the component definition MUST NOT have a cpu-limit or cpu-request configured
+	MinCPULimit        CPUSize
+	MaxCPULimit        CPUSize
+	DefaultCPULimit    CPUSize
+	DefaultCPURequest  CPUSize
+
+	// Defaults for health checks that do not configure them.
+	HealthCheckInterval           Duration
+	HealthCheckTimeout            Duration
+	HealthCheckHealthyThreshold   int
+	HealthCheckUnhealthyThreshold int
+
+	// Defaults for components that do not configure restart and stop behavior.
+	RestartPolicy     RestartPolicy
+	RestartMaxRetries int
+	StopSignal        string
+	StopTimeout       Duration
+	MaxStopTimeout    Duration // If set, the stop-timeout of a component MUST NOT exceed this
+
+	// Image policies
+	RequireImageTag    bool     // If true, images MUST have a tag
+	RejectLatestTag    bool     // If true, images MUST NOT use the latest tag, neither explicitly nor implicitly
+	RequireImageDigest bool     // If true, images MUST be pinned to a digest, e.g. for production
+	AllowedRegistries  []string // If set, the registry of an image MUST match one of these patterns, e.g. "*.giantswarm.io"
+	DeniedRegistries   []string // The registry of an image MUST NOT match any of these patterns
+
+	// RegistryPolicies are checked in order, the first one matching the registry of an image applies. If set,
+	// images of registries not matching any policy are rejected.
+	RegistryPolicies []RegistryPolicy
+
+	// DomainRegistry is used to check that domains are not bound by other services. If nil, this check is skipped.
+	DomainRegistry DomainRegistry
+
+	// DomainPolicy decides whether Org may bind a domain. If nil, all domains are allowed.
+	DomainPolicy DomainPolicy
 
 	// RestrictedRegistries contains the registry names, where the validator should throw an error, if the repository
 	// namespace does not contain the Org
@@ -94,6 +149,18 @@ func (sd *ServiceDefinition) Validate(valCtx *ValidationContext) error {
 		return mask(err)
 	}
 
+	if valCtx != nil && valCtx.DomainRegistry != nil {
+		if err := sd.validateDomainRegistry(valCtx.DomainRegistry); err != nil {
+			return mask(err)
+		}
+	}
+
+	if valCtx != nil && valCtx.DomainPolicy != nil {
+		if err := sd.validateDomainPolicy(valCtx.Org, valCtx.DomainPolicy); err != nil {
+			return mask(err)
+		}
+	}
+
 	return nil
 }
 
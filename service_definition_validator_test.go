@@ -139,7 +139,7 @@ func TestServiceDefCannotFixFieldName(t *testing.T) {
 	if err == nil {
 		t.Fatalf("json.Unmarshal NOT failed")
 	}
-	if err.Error() != `unknown JSON field: ["components"]["foo/bar"]["ima_ge"]` {
+	if err.Error() != `unknown JSON field: ["components"]["foo/bar"]["ima_ge"], did you mean 'image'?` {
 		t.Fatalf("expected proper error, got: %s", err.Error())
 	}
 	if !userconfig.IsUnknownJsonField(err) {
@@ -191,3 +191,75 @@ func TestUnmarshalServiceDefUnknownField(t *testing.T) {
 		t.Fatalf("expetced error to be UnknownJSONFieldError")
 	}
 }
+
+func TestUnmarshalServiceDefNestedUnknownField(t *testing.T) {
+	list := []struct {
+		Component string
+		Message   string
+		Field     string
+	}{
+		{`{ "links": [ { "component": "component/b", "target_prot": 80 } ] }`,
+			`unknown JSON field: ["components"]["api"]["links"][0]["target_prot"], did you mean 'target_port'?`, "links[0].target_prot"},
+		{`{ "domains": { "api.example.com": { "port": 80, "redirect-http": true } } }`,
+			`unknown JSON field: ["components"]["api"]["domains"]["api.example.com"]["redirect-http"], did you mean 'redirect-https'?`, `domains["api.example.com"].redirect-http`},
+		{`{ "scale": { "min": 1, "mx": 2 } }`,
+			`unknown JSON field: ["components"]["api"]["scale"]["mx"], did you mean 'max'?`, "scale.mx"},
+		{`{ "healthcheck": { "http": { "path": "/", "port": 80, "statsu": 200 } } }`,
+			`unknown JSON field: ["components"]["api"]["healthcheck"]["http"]["statsu"], did you mean 'status'?`, "healthcheck.http.statsu"},
+	}
+
+	for i, test := range list {
+		b := []byte(`{ "components": { "api": ` + test.Component + ` } }`)
+
+		var def userconfig.ServiceDefinition
+		err := json.Unmarshal(b, &def)
+		if !userconfig.IsUnknownJsonField(err) {
+			t.Fatalf("Test %d: expected UnknownJSONFieldError, got %v", i, err)
+		}
+		if err.Error() != test.Message {
+			t.Fatalf("Test %d: expected error '%s', got '%s'", i, test.Message, err.Error())
+		}
+		d, ok := userconfig.DiagnosticOf(err)
+		if !ok || d.Component != "api" || d.Field != test.Field {
+			t.Fatalf("Test %d: expected diagnostic in component 'api' and field '%s', got %#v", i, test.Field, d)
+		}
+	}
+}
+
+func TestUnmarshalComponentDefUnknownField(t *testing.T) {
+	b := []byte(`{ "image": "registry/namespace/repository:version", "volumes": [ { "pth": "/data", "size": "5 GB" } ] }`)
+
+	var nd userconfig.ComponentDefinition
+	err := json.Unmarshal(b, &nd)
+	if !userconfig.IsUnknownJsonField(err) {
+		t.Fatalf("expected UnknownJSONFieldError, got %v", err)
+	}
+	if err.Error() != `unknown JSON field: ["volumes"][0]["pth"], did you mean 'path'?` {
+		t.Fatalf("expected proper error, got: %s", err.Error())
+	}
+}
+
+func TestParseServiceDefCPU(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"component/a": {
+				"image": "registry/namespace/repository:version",
+				"cpu-limit": 2,
+				"cpu-request": "500m"
+			}
+		}
+	}`)
+
+	var appDef userconfig.ServiceDefinition
+	if err := json.Unmarshal(b, &appDef); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	componentA := appDef.Components["component/a"]
+	if componentA.CPULimit != "2" {
+		t.Fatalf("invalid cpu-limit: %s", componentA.CPULimit)
+	}
+	if componentA.CPURequest != "500m" {
+		t.Fatalf("invalid cpu-request: %s", componentA.CPURequest)
+	}
+}
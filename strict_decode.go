@@ -0,0 +1,278 @@
+package userconfig
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decodeStrict unmarshals the given JSON into v like json.Unmarshal does, but
+// returns an UnknownJSONFieldError for object keys that do not match a field
+// of the struct they are decoded into. Values that implement json.Unmarshaler
+// are left to their UnmarshalJSON, so custom types use decodeStrict for their
+// own object formats. The unknown fields they report are prefixed with the
+// path leading to them, e.g. `["volumes"][0]["pth"]`.
+func decodeStrict(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return maskf(InvalidArgumentError, "cannot decode into %T", v)
+	}
+	if err := decodeStrictValue(data, rv.Elem()); err != nil {
+		return mask(err)
+	}
+	return nil
+}
+
+// decodeStrictValue decodes the given JSON into the given addressable value.
+func decodeStrictValue(data []byte, v reflect.Value) error {
+	if string(bytes.TrimSpace(data)) == "null" || isJSONUnmarshaler(v.Type()) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeStrictValue(data, v.Elem())
+	case reflect.Struct:
+		return decodeStrictStruct(data, v)
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String && !isJSONUnmarshaler(v.Type().Key()) {
+			return decodeStrictMap(data, v)
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return decodeStrictSlice(data, v)
+		}
+	}
+
+	// Values without fields of their own
+	return json.Unmarshal(data, v.Addr().Interface())
+}
+
+// isJSONUnmarshaler returns true if pointers to the given type unmarshal
+// themselves.
+func isJSONUnmarshaler(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return pt.Implements(jsonUnmarshalerType) || pt.Implements(textUnmarshalerType)
+}
+
+func decodeStrictStruct(data []byte, v reflect.Value) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		// Not an object, let json report the mismatch
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	fields := jsonFields(v.Type())
+	for _, key := range sortedJSONKeys(object) {
+		f, ok := fields[key]
+		if !ok {
+			return unknownJSONFieldError(fmt.Sprintf("[%q]", key), jsonFieldNames(fields))
+		}
+		if err := decodeStrictValue(object[key], v.FieldByIndex(f.Index)); err != nil {
+			return inJSONPath(err, fmt.Sprintf("[%q]", key))
+		}
+	}
+
+	return nil
+}
+
+func decodeStrictMap(data []byte, v reflect.Value) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	t := v.Type()
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(t))
+	}
+	for _, key := range sortedJSONKeys(object) {
+		elem := reflect.New(t.Elem()).Elem()
+		if err := decodeStrictValue(object[key], elem); err != nil {
+			return inJSONPath(err, fmt.Sprintf("[%q]", key))
+		}
+		v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+	}
+
+	return nil
+}
+
+func decodeStrictSlice(data []byte, v reflect.Value) error {
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	s := reflect.MakeSlice(v.Type(), len(list), len(list))
+	for i, raw := range list {
+		if err := decodeStrictValue(raw, s.Index(i)); err != nil {
+			return inJSONPath(err, fmt.Sprintf("[%d]", i))
+		}
+	}
+	v.Set(s)
+
+	return nil
+}
+
+// sortedJSONKeys returns the keys of the given object sorted, so the first
+// unknown field reported does not depend on map order.
+func sortedJSONKeys(object map[string]json.RawMessage) []string {
+	keys := []string{}
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// inJSONPath prefixes the path of the given unknown field error with the
+// given key, e.g. `["volumes"]` or `[0]`. Other errors are returned as they
+// are.
+func inJSONPath(err error, key string) error {
+	d, ok := DiagnosticOf(err)
+	if !ok || d.jsonPath == "" {
+		return err
+	}
+	return unknownJSONFieldError(key+d.jsonPath, d.jsonNames)
+}
+
+// jsonPathRegExp matches the keys of a JSON path, e.g. `["components"]` or
+// `[0]`.
+var jsonPathRegExp = regexp.MustCompile(`\[("(?:[^"\\]|\\.)*"|[0-9]+)\]`)
+
+// unknownJSONFieldError returns an UnknownJSONFieldError for the given path,
+// e.g. `["components"]["api"]["volums"]`. If the unknown field is close to one
+// of the given names of the fields known there, it is suggested.
+func unknownJSONFieldError(path string, names []string) *Diagnostic {
+	d := newDiagnostic(UnknownJSONFieldError, "unknown JSON field: %s", path)
+	d.jsonPath = path
+	d.jsonNames = names
+
+	keys := []string{}
+	for _, m := range jsonPathRegExp.FindAllStringSubmatch(path, -1) {
+		key, err := strconv.Unquote(m[1])
+		if err != nil {
+			key = m[1]
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return d
+	}
+
+	name := keys[len(keys)-1]
+	d.withValue(name)
+	if len(keys) > 2 && keys[0] == "components" {
+		d.withComponent(ComponentName(keys[1]))
+		d.withField(jsonFieldPath(reflect.TypeOf(ComponentDefinition{}), keys[2:]))
+	}
+
+	return d.withSuggestion(name, names)
+}
+
+// jsonElemTypes maps types to the type of their elements in JSON, where it
+// differs from Go, e.g. domains given in the format domain: definition.
+var jsonElemTypes = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(V2DomainDefinitions{}): reflect.TypeOf(DomainSettings{}),
+}
+
+// jsonFieldPath formats the given keys as field path within the given type,
+// e.g. "volumes[0].path" or `domains["example.com"].tls`.
+func jsonFieldPath(t reflect.Type, keys []string) string {
+	path := ""
+	for _, key := range keys {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch {
+		case t != nil && t.Kind() == reflect.Struct:
+			if path != "" {
+				path += "."
+			}
+			path += key
+			t = jsonFieldType(t, key)
+		case t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+			path += "[" + key + "]"
+			t = t.Elem()
+		default:
+			path += fmt.Sprintf("[%q]", key)
+			if elem, ok := jsonElemTypes[t]; ok {
+				t = elem
+			} else if t != nil && t.Kind() == reflect.Map {
+				t = t.Elem()
+			} else {
+				t = nil
+			}
+		}
+	}
+	return path
+}
+
+// jsonFieldType returns the type of the field of the given struct with the
+// given JSON name, or nil if there is no such field.
+func jsonFieldType(t reflect.Type, name string) reflect.Type {
+	f, ok := jsonFields(t)[name]
+	if !ok {
+		return nil
+	}
+	return f.Type
+}
+
+// jsonFieldNames returns the sorted JSON names of the given fields.
+func jsonFieldNames(fields map[string]reflect.StructField) []string {
+	names := []string{}
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// jsonFields returns the fields of the given struct by JSON name, including
+// the fields of embedded structs, with their index within the given struct.
+// Like encoding/json, fields of the struct itself hide those of embedded
+// structs.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	embedded := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for embeddedName, ef := range jsonFields(f.Type) {
+				ef.Index = append([]int{i}, ef.Index...)
+				embedded[embeddedName] = ef
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f
+	}
+	for name, f := range embedded {
+		if _, ok := fields[name]; !ok {
+			fields[name] = f
+		}
+	}
+	return fields
+}
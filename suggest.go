@@ -0,0 +1,135 @@
+package userconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestionDistance is the largest edit distance of a suggestion to the
+// name it replaces. Shorter names allow less, see suggest.
+const maxSuggestionDistance = 2
+
+// suggest returns the candidate closest to the given name, if it is close
+// enough to be a likely typo, e.g. "volumes" for "volums". Names are compared
+// case insensitive. Ties are broken by the order of the candidates.
+//
+// Candidates whose distinct part is entirely different from that of the name
+// are not suggested, e.g. "component/a" for "component/c".
+func suggest(name string, candidates []string) (string, bool) {
+	limit := len(name) / 3
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > maxSuggestionDistance {
+		limit = maxSuggestionDistance
+	}
+
+	best, bestDistance := "", limit+1
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		a, b := distinctParts(strings.ToLower(name), strings.ToLower(candidate))
+		d := editDistance(a, b)
+		if a != "" && b != "" && d >= len(a) && d >= len(b) {
+			continue
+		}
+		if d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best, best != ""
+}
+
+// distinctParts returns the given strings without their common prefix and
+// suffix, e.g. "s" and "es" for "volums" and "volumes".
+func distinctParts(a, b string) (string, string) {
+	ra, rb := []rune(a), []rune(b)
+	for len(ra) > 0 && len(rb) > 0 && ra[0] == rb[0] {
+		ra, rb = ra[1:], rb[1:]
+	}
+	for len(ra) > 0 && len(rb) > 0 && ra[len(ra)-1] == rb[len(rb)-1] {
+		ra, rb = ra[:len(ra)-1], rb[:len(rb)-1]
+	}
+	return string(ra), string(rb)
+}
+
+// editDistance returns the edit distance of the given strings, that is the
+// number of single character insertions, deletions, substitutions and
+// transpositions of adjacent characters needed to turn a into b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// withSuggestion adds a "did you mean" hint to the message of this diagnostic
+// and suggests the replacement as fix, if one of the given candidates is
+// close to the given name.
+func (d *Diagnostic) withSuggestion(name string, candidates []string) *Diagnostic {
+	if s, ok := suggest(name, candidates); ok {
+		d.Message = fmt.Sprintf("%s, did you mean '%s'?", d.Message, s)
+		d.Fix = fmt.Sprintf("replace '%s' by '%s'", name, s)
+	}
+	return d
+}
+
+// portCandidates returns the given ports and the ports of the given expose
+// definitions sorted, for use with suggest.
+func portCandidates(pds PortDefinitions, eds ExposeDefinitions) []string {
+	list := []string{}
+	for _, port := range pds {
+		list = append(list, port.String())
+	}
+	for _, ed := range eds {
+		list = append(list, ed.Port.String())
+	}
+	sort.Strings(list)
+
+	return list
+}
+
+// portNameCandidates returns the sorted port names of the given component,
+// for use with suggest.
+func portNameCandidates(nd *ComponentDefinition) []string {
+	list := []string{}
+	for name := range nd.PortNames {
+		list = append(list, name.String())
+	}
+	sort.Strings(list)
+
+	return list
+}
@@ -0,0 +1,122 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/generic-types-go"
+	"github.com/giantswarm/user-config"
+)
+
+func TestUnknownFieldSuggestions(t *testing.T) {
+	list := []struct {
+		Component string
+		Message   string
+		Field     string
+	}{
+		{`{ "image": "registry/namespace/api:1.0", "volums": [] }`,
+			`unknown JSON field: ["components"]["api"]["volums"], did you mean 'volumes'?`, "volums"},
+		{`{ "image": "registry/namespace/api:1.0", "volumes": [ { "pth": "/data", "size": "5 GB" } ] }`,
+			`unknown JSON field: ["components"]["api"]["volumes"][0]["pth"], did you mean 'path'?`, "volumes[0].pth"},
+		{`{ "image": "registry/namespace/api:1.0", "memory-limt": "512M" }`,
+			`unknown JSON field: ["components"]["api"]["memory-limt"], did you mean 'memory-limit'?`, "memory-limt"},
+		{`{ "image": "registry/namespace/api:1.0", "unknown": "unknown" }`,
+			`unknown JSON field: ["components"]["api"]["unknown"]`, "unknown"},
+	}
+
+	for i, test := range list {
+		b := []byte(`{ "components": { "api": ` + test.Component + ` } }`)
+
+		var def userconfig.ServiceDefinition
+		err := json.Unmarshal(b, &def)
+		if !userconfig.IsUnknownJsonField(err) {
+			t.Fatalf("Test %d: expected UnknownJSONFieldError, got %v", i, err)
+		}
+		if err.Error() != test.Message {
+			t.Fatalf("Test %d: expected error '%s', got '%s'", i, test.Message, err.Error())
+		}
+		d, ok := userconfig.DiagnosticOf(err)
+		if !ok {
+			t.Fatalf("Test %d: expected diagnostic, got %#v", i, err)
+		}
+		if d.Component != "api" || d.Field != test.Field {
+			t.Fatalf("Test %d: expected component 'api' and field '%s', got '%s' and '%s'", i, test.Field, d.Component, d.Field)
+		}
+	}
+}
+
+func TestReferenceSuggestions(t *testing.T) {
+	list := []struct {
+		Modify     func(def *userconfig.ServiceDefinition)
+		Suggestion string
+	}{
+		// Component name
+		{func(def *userconfig.ServiceDefinition) {
+			def.Components["component/a"].Links = userconfig.LinkDefinitions{
+				{Component: "component/bb", TargetPort: generictypes.MustParseDockerPort("80/tcp")},
+			}
+		}, "did you mean 'component/b'?"},
+		// Exported port
+		{func(def *userconfig.ServiceDefinition) {
+			def.Components["component/b"].Ports = userconfig.PortDefinitions{generictypes.MustParseDockerPort("8080/tcp")}
+			def.Components["component/a"].Links = userconfig.LinkDefinitions{
+				{Component: "component/b", TargetPort: generictypes.MustParseDockerPort("808/tcp")},
+			}
+		}, "did you mean '8080/tcp'?"},
+		// Port name
+		{func(def *userconfig.ServiceDefinition) {
+			def.Components["component/b"].PortNames = userconfig.PortNames{"http": generictypes.MustParseDockerPort("80/tcp")}
+			def.Components["component/a"].Links = userconfig.LinkDefinitions{
+				{Component: "component/b", TargetPortName: "htp"},
+			}
+		}, "did you mean 'http'?"},
+		// Entirely different names are not suggested
+		{func(def *userconfig.ServiceDefinition) {
+			def.Components["component/a"].Links = userconfig.LinkDefinitions{
+				{Component: "component/c", TargetPort: generictypes.MustParseDockerPort("80/tcp")},
+			}
+		}, ""},
+	}
+
+	for i, test := range list {
+		def := ExampleDefinition()
+		test.Modify(&def)
+
+		err := def.Validate(nil)
+		if err == nil {
+			t.Fatalf("Test %d: expected validation to fail", i)
+		}
+		if test.Suggestion == "" {
+			if strings.Contains(err.Error(), "did you mean") {
+				t.Fatalf("Test %d: expected no suggestion, got '%s'", i, err.Error())
+			}
+			continue
+		}
+		if !strings.HasSuffix(err.Error(), test.Suggestion) {
+			t.Fatalf("Test %d: expected suggestion \"%s\", got '%s'", i, test.Suggestion, err.Error())
+		}
+		if d, ok := userconfig.DiagnosticOf(err); !ok || d.Fix == "" {
+			t.Fatalf("Test %d: expected diagnostic with fix, got %#v", i, err)
+		}
+	}
+}
+
+func TestLinkAliasSuggestions(t *testing.T) {
+	def := ExampleDefinition()
+	def.Components["component/a"].Links = userconfig.LinkDefinitions{
+		{Component: "component/b", Alias: "postgres", TargetPort: generictypes.MustParseDockerPort("80/tcp")},
+	}
+	def.Components["component/a"].Env = userconfig.EnvList{"DB_HOST=postgress", "DB=$POSTGRE_PORT_80_TCP_ADDR"}
+
+	warnings := def.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %v", warnings)
+	}
+	if !strings.HasSuffix(warnings[0].Message, "did you mean 'postgres'?") {
+		t.Fatalf("expected suggestion of 'postgres', got '%s'", warnings[0].Message)
+	}
+	if !strings.HasSuffix(warnings[1].Message, "did you mean link 'postgres'?") {
+		t.Fatalf("expected suggestion of link 'postgres', got '%s'", warnings[1].Message)
+	}
+}
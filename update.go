@@ -0,0 +1,139 @@
+package userconfig
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// UpdateDefinition describes how a new version of a component is rolled out
+// to its instances.
+type UpdateDefinition struct {
+	// Maximum number of instances that can be unavailable during an update.
+	MaxUnavailable int `json:"max-unavailable,omitempty" description:"Maximum number of instances that can be unavailable during an update"`
+
+	// Maximum number of instances that can be created above the desired number during an update.
+	MaxSurge int `json:"max-surge,omitempty" description:"Maximum number of instances that can be created above the desired number during an update"`
+
+	// Time to wait between two batches of updated instances, e.g. "30s".
+	Delay Duration `json:"delay,omitempty" description:"Time to wait between two batches of updated instances, e.g. '30s'"`
+
+	// Number of instances to update first, before the update continues.
+	Canary int `json:"canary,omitempty" description:"Number of instances to update first, before the update continues"`
+
+	// If true, the update is rolled back when updated instances fail their health checks.
+	AutoRollback bool `json:"auto-rollback,omitempty" description:"If true, the update is rolled back when updated instances fail their health checks"`
+}
+
+// String returns the marshalled string represantion of its own incarnation.
+// We use it to compare two UpdateDefinitions when creating a diff. See
+// diff.go
+func (ud *UpdateDefinition) String() string {
+	if ud == nil {
+		return ""
+	}
+
+	raw, err := json.Marshal(ud)
+	if err != nil {
+		panic(fmt.Sprintf("%#v\n", mask(err)))
+	}
+
+	return string(raw)
+}
+
+// validateUpdate checks the update strategy of this component against its
+// scaling settings.
+func (nd *ComponentDefinition) validateUpdate(valCtx *ValidationContext) error {
+	ud := nd.Update
+	if ud == nil {
+		return nil
+	}
+
+	if nd.IsJob() {
+		return newDiagnostic(InvalidUpdateStrategyError, "jobs cannot have an update strategy").withFix("remove the update strategy")
+	}
+
+	if ud.MaxUnavailable < 0 {
+		return newDiagnostic(InvalidUpdateStrategyError, "max-unavailable '%d' cannot be negative", ud.MaxUnavailable).withField("max-unavailable").withValue(ud.MaxUnavailable)
+	}
+
+	if ud.MaxSurge < 0 {
+		return newDiagnostic(InvalidUpdateStrategyError, "max-surge '%d' cannot be negative", ud.MaxSurge).withField("max-surge").withValue(ud.MaxSurge)
+	}
+
+	if ud.Canary < 0 {
+		return newDiagnostic(InvalidUpdateStrategyError, "canary '%d' cannot be negative", ud.Canary).withField("canary").withValue(ud.Canary)
+	}
+
+	if !ud.Delay.IsEmpty() && !ud.Delay.Valid() {
+		return newDiagnostic(InvalidUpdateStrategyError, "invalid delay '%s'", ud.Delay).withField("delay").withValue(ud.Delay)
+	}
+
+	if ud.AutoRollback && nd.HealthCheck == nil {
+		return newDiagnostic(InvalidUpdateStrategyError, "auto-rollback needs a healthcheck").withField("auto-rollback").withFix("add a healthcheck")
+	}
+
+	if ud.MaxUnavailable == 0 && ud.MaxSurge == 0 {
+		return newDiagnostic(InvalidUpdateStrategyError, "max-unavailable and max-surge cannot be 0 both, since the update could not make progress").withField("max-surge").withValue(ud.MaxSurge).withFix("set max-unavailable or max-surge to at least 1")
+	}
+
+	// Unset bounds are filled in by the defaults later on
+	min, max := 0, 0
+	if nd.Scale != nil {
+		min, max = nd.Scale.Min, nd.Scale.Max
+	}
+	if min == 0 && valCtx != nil {
+		min = valCtx.MinScaleSize
+	}
+	if max == 0 && valCtx != nil {
+		max = valCtx.MaxScaleSize
+	}
+
+	if min > 0 && ud.MaxUnavailable >= min {
+		return newDiagnostic(InvalidUpdateStrategyError, "max-unavailable '%d' must be less than scale min '%d', so at least one instance keeps running", ud.MaxUnavailable, min).withField("max-unavailable").withValue(ud.MaxUnavailable)
+	}
+
+	if max == 0 {
+		return nil
+	}
+
+	if ud.MaxUnavailable > max {
+		return newDiagnostic(InvalidUpdateStrategyError, "max-unavailable '%d' cannot be greater than scale max '%d'", ud.MaxUnavailable, max).withField("max-unavailable").withValue(ud.MaxUnavailable)
+	}
+
+	if ud.Canary >= max && ud.Canary != 0 {
+		return newDiagnostic(InvalidUpdateStrategyError, "canary '%d' must be less than scale max '%d'", ud.Canary, max).withField("canary").withValue(ud.Canary)
+	}
+
+	return nil
+}
+
+// validateUpdateStrategyInPods checks that all update strategies within a pod
+// are either not set or the same, since all components of a pod are updated
+// together.
+func (nds *ComponentDefinitions) validateUpdateStrategyInPods() error {
+	for componentName, componentDef := range *nds {
+		if !componentDef.IsPodRoot() {
+			continue
+		}
+
+		podComponents, err := nds.PodComponents(componentName)
+		if err != nil {
+			return mask(err)
+		}
+
+		var update *UpdateDefinition
+		for _, key := range orderedComponentKeys(podComponents) {
+			name := ComponentName(key)
+			c := podComponents[name]
+			if c.Update == nil {
+				continue
+			}
+			if update != nil && update.String() != c.Update.String() {
+				return newDiagnostic(InvalidUpdateStrategyError, "different update strategies in pod under '%s'", componentName.String()).withComponent(name).withField("update").withValue(c.Update)
+			}
+			update = c.Update
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package userconfig_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/giantswarm/generic-types-go"
+	"github.com/giantswarm/user-config"
+)
+
+func TestParseUpdateStrategy(t *testing.T) {
+	b := []byte(`{
+		"components": {
+			"api": {
+				"image": "registry/namespace/repository:version",
+				"ports": [ "8080/tcp" ],
+				"scale": { "min": 2, "max": 6 },
+				"healthcheck": { "tcp": { "port": "8080/tcp" } },
+				"update": {
+					"max-unavailable": 1,
+					"max-surge": 2,
+					"delay": "30s",
+					"canary": 1,
+					"auto-rollback": true
+				}
+			}
+		}
+	}`)
+
+	var serviceDef userconfig.ServiceDefinition
+	if err := json.Unmarshal(b, &serviceDef); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	ud := serviceDef.Components["api"].Update
+	if ud == nil || ud.MaxUnavailable != 1 || ud.MaxSurge != 2 || ud.Delay != "30s" || ud.Canary != 1 || !ud.AutoRollback {
+		t.Fatalf("invalid update strategy: %s", ud.String())
+	}
+
+	if err := serviceDef.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+}
+
+func TestUpdateStrategyValidation(t *testing.T) {
+	healthCheck := &userconfig.HealthCheckDefinition{TCP: &userconfig.TCPHealthCheck{Port: generictypes.MustParseDockerPort("80/tcp")}}
+
+	tests := []struct {
+		Scale       *userconfig.ScaleDefinition
+		HealthCheck *userconfig.HealthCheckDefinition
+		Update      *userconfig.UpdateDefinition
+		Valid       bool
+	}{
+		// Valid ones
+		{nil, nil, &userconfig.UpdateDefinition{MaxUnavailable: 1, MaxSurge: 1}, true},
+		{&userconfig.ScaleDefinition{Min: 1, Max: 3}, healthCheck, &userconfig.UpdateDefinition{MaxSurge: 1, Canary: 2, AutoRollback: true, Delay: "1m"}, true},
+		{&userconfig.ScaleDefinition{Min: 3, Max: 5}, nil, &userconfig.UpdateDefinition{MaxUnavailable: 2}, true},
+
+		// Invalid ones
+		{nil, nil, &userconfig.UpdateDefinition{MaxUnavailable: -1}, false},
+		{nil, nil, &userconfig.UpdateDefinition{MaxSurge: -1}, false},
+		{nil, nil, &userconfig.UpdateDefinition{Canary: -1}, false},
+		{nil, nil, &userconfig.UpdateDefinition{MaxSurge: 1, Delay: "slowly"}, false},
+		{nil, nil, &userconfig.UpdateDefinition{MaxSurge: 1, AutoRollback: true}, false},
+		{nil, nil, &userconfig.UpdateDefinition{Delay: "1m"}, false},
+		{&userconfig.ScaleDefinition{Min: 2, Max: 5}, nil, &userconfig.UpdateDefinition{MaxUnavailable: 2}, false},
+		{&userconfig.ScaleDefinition{Min: 1, Max: 3}, nil, &userconfig.UpdateDefinition{MaxUnavailable: 4}, false},
+		{&userconfig.ScaleDefinition{Min: 1, Max: 3}, nil, &userconfig.UpdateDefinition{MaxSurge: 1, Canary: 3}, false},
+	}
+
+	for i, test := range tests {
+		def := ExampleDefinition()
+		def.Components["component/a"].Scale = test.Scale
+		def.Components["component/a"].HealthCheck = test.HealthCheck
+		def.Components["component/a"].Update = test.Update
+
+		err := def.Validate(nil)
+		if test.Valid && err != nil {
+			t.Fatalf("test %d: expected definition to be valid, got error: %#v", i, err)
+		}
+		if !test.Valid && !userconfig.IsInvalidUpdateStrategy(err) {
+			t.Fatalf("test %d: expected error to be InvalidUpdateStrategyError, got: %#v", i, err)
+		}
+	}
+}
+
+func TestUpdateStrategyInPods(t *testing.T) {
+	service := testService()
+	service.Components["pod"] = setPod(&userconfig.ComponentDefinition{}, userconfig.PodChildren)
+	service.Components["pod/a"] = testComponent()
+	service.Components["pod/a"].Update = &userconfig.UpdateDefinition{MaxSurge: 1}
+	service.Components["pod/b"] = testComponent()
+
+	if err := service.Validate(nil); err != nil {
+		t.Fatalf("expected definition to be valid, got error: %#v", err)
+	}
+
+	service.Components["pod/b"].Update = &userconfig.UpdateDefinition{MaxSurge: 2}
+	if err := service.Validate(nil); !userconfig.IsInvalidUpdateStrategy(err) {
+		t.Fatalf("expected error to be InvalidUpdateStrategyError, got: %#v", err)
+	}
+}
+
+func TestUpdateStrategyDiffRequiresNoRestart(t *testing.T) {
+	oldDef := ExampleDefinition()
+	newDef := ExampleDefinition()
+	newDef.Components["component/a"].Update = &userconfig.UpdateDefinition{MaxSurge: 1}
+
+	diffInfos := userconfig.ServiceDiff(oldDef, newDef)
+	if len(userconfig.DiffInfosByType(diffInfos, userconfig.DiffTypeComponentUpdateStrategyUpdated)) != 1 {
+		t.Fatalf("expected update strategy diff: %#v", diffInfos)
+	}
+	if len(diffInfos.RequiringRestart()) != 0 {
+		t.Fatalf("expected no diff to require a restart: %#v", diffInfos.RequiringRestart())
+	}
+
+	newDef.Components["component/a"].Args = []string{"--verbose"}
+	diffInfos = userconfig.ServiceDiff(oldDef, newDef)
+	if len(diffInfos.RequiringRestart()) != 1 {
+		t.Fatalf("expected args diff to require a restart: %#v", diffInfos.RequiringRestart())
+	}
+}
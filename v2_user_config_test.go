@@ -203,6 +203,29 @@ func TestAbsentServiceName(t *testing.T) {
 	}
 }
 
+// TestAbsentServiceNameUnchanged checks that the generated name of existing
+// definitions does not change when new fields are added.
+func TestAbsentServiceNameUnchanged(t *testing.T) {
+	b := []byte(`{ "components": { "api": {
+		"image": "busybox:1.0",
+		"ports": [ "80/tcp" ],
+		"scale": { "min": 1, "max": 2 },
+		"volumes": [ { "path": "/data", "size": "5 GB" } ]
+	}}}`)
+	a, err := userconfig.ParseServiceDefinition(b)
+	if err != nil {
+		t.Fatalf("ParseServiceDefinition failed: %#v", err)
+	}
+	name, err := a.Name()
+	if err != nil {
+		t.Fatalf("Name failed: %#v", err)
+	}
+	expectedName := "3a9b356c"
+	if name != expectedName {
+		t.Fatalf("Name result is invalid, got '%s', expected '%s'", name, expectedName)
+	}
+}
+
 func TestSpecifiedServiceName(t *testing.T) {
 	a := ExampleDefinition()
 	expectedName := "nice-he"
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 )
 
 type VolumeConfig struct {
@@ -35,29 +36,29 @@ func (vc *VolumeConfig) validate() error {
 	// Option 1
 	if vc.Path != "" && !vc.Size.Empty() {
 		if vc.VolumesFrom != "" {
-			return maskf(InvalidVolumeConfigError, "volumes-from for path '%s' should be empty", vc.Path)
+			return newDiagnostic(InvalidVolumeConfigError, "volumes-from for path '%s' should be empty", vc.Path).withField("volumes-from").withValue(vc.VolumesFrom)
 		}
 		if vc.VolumeFrom != "" {
-			return maskf(InvalidVolumeConfigError, "volume-from for path '%s' should be empty", vc.Path)
+			return newDiagnostic(InvalidVolumeConfigError, "volume-from for path '%s' should be empty", vc.Path).withField("volume-from").withValue(vc.VolumeFrom)
 		}
 		if vc.VolumePath != "" {
-			return maskf(InvalidVolumeConfigError, "volume-path for path '%s' should be empty", vc.Path)
+			return newDiagnostic(InvalidVolumeConfigError, "volume-path for path '%s' should be empty", vc.Path).withField("volume-path").withValue(vc.VolumePath)
 		}
 		return nil
 	}
 	// Option 2
 	if vc.VolumesFrom != "" {
 		if vc.Path != "" {
-			return maskf(InvalidVolumeConfigError, "path for volumes-from '%s' should be empty", vc.VolumesFrom)
+			return newDiagnostic(InvalidVolumeConfigError, "path for volumes-from '%s' should be empty", vc.VolumesFrom).withField("path").withValue(vc.Path)
 		}
 		if !vc.Size.Empty() {
-			return maskf(InvalidVolumeConfigError, "size for volumes-from '%s' should be empty", vc.VolumesFrom)
+			return newDiagnostic(InvalidVolumeConfigError, "size for volumes-from '%s' should be empty", vc.VolumesFrom).withField("size").withValue(vc.Size)
 		}
 		if vc.VolumeFrom != "" {
-			return maskf(InvalidVolumeConfigError, "volume-from for volumes-from '%s' should be empty", vc.VolumesFrom)
+			return newDiagnostic(InvalidVolumeConfigError, "volume-from for volumes-from '%s' should be empty", vc.VolumesFrom).withField("volume-from").withValue(vc.VolumeFrom)
 		}
 		if vc.VolumePath != "" {
-			return maskf(InvalidVolumeConfigError, "volume-path for volumes-from '%s' should be empty", vc.VolumesFrom)
+			return newDiagnostic(InvalidVolumeConfigError, "volume-path for volumes-from '%s' should be empty", vc.VolumesFrom).withField("volume-path").withValue(vc.VolumePath)
 		}
 		return nil
 	}
@@ -66,19 +67,19 @@ func (vc *VolumeConfig) validate() error {
 		// Path is optional
 
 		if !vc.Size.Empty() {
-			return maskf(InvalidVolumeConfigError, "size for volume-from '%s' should be empty", vc.VolumeFrom)
+			return newDiagnostic(InvalidVolumeConfigError, "size for volume-from '%s' should be empty", vc.VolumeFrom).withField("size").withValue(vc.Size)
 		}
 		if vc.VolumesFrom != "" {
-			return maskf(InvalidVolumeConfigError, "volumes-from for volume-from '%s' should be empty", vc.VolumeFrom)
+			return newDiagnostic(InvalidVolumeConfigError, "volumes-from for volume-from '%s' should be empty", vc.VolumeFrom).withField("volumes-from").withValue(vc.VolumesFrom)
 		}
 		if vc.VolumePath == "" {
-			return maskf(InvalidVolumeConfigError, "volume-path for volume-from '%s' should not be empty", vc.VolumeFrom)
+			return newDiagnostic(InvalidVolumeConfigError, "volume-path for volume-from '%s' should not be empty", vc.VolumeFrom).withField("volume-path")
 		}
 		return nil
 	}
 
 	// No valid option detected.
-	return maskf(InvalidVolumeConfigError, "path & size, volume-path or volumes-path must be set in '%#v'", vc)
+	return newDiagnostic(InvalidVolumeConfigError, "path & size, volume-path or volumes-path must be set in '%#v'", vc).withFix("set path and size, volumes-from, or volume-from and volume-path")
 }
 
 func (vc VolumeConfig) V2Validate(valCtx *ValidationContext) error {
@@ -89,7 +90,7 @@ func (vc VolumeConfig) V2Validate(valCtx *ValidationContext) error {
 	if vc.Path != "" && vc.Size != "" {
 		intSize, err := vc.Size.SizeInGB()
 		if err != nil {
-			return maskf(InvalidVolumeConfigError, "invalid volume size '%s', expected '<number> GB'", vc.Size)
+			return newDiagnostic(InvalidVolumeConfigError, "invalid volume size '%s', expected '<number> GB'", vc.Size).withField("size").withValue(vc.Size)
 		}
 
 		min, err := valCtx.MinVolumeSize.SizeInGB()
@@ -98,7 +99,7 @@ func (vc VolumeConfig) V2Validate(valCtx *ValidationContext) error {
 		}
 
 		if intSize < min {
-			return maskf(InvalidVolumeConfigError, "volume size '%d' cannot be less than '%d'", intSize, min)
+			return newDiagnostic(InvalidVolumeConfigError, "volume size '%d' cannot be less than '%d'", intSize, min).withField("size").withValue(vc.Size)
 		}
 
 		max, err := valCtx.MaxVolumeSize.SizeInGB()
@@ -107,7 +108,7 @@ func (vc VolumeConfig) V2Validate(valCtx *ValidationContext) error {
 		}
 
 		if intSize > max {
-			return maskf(InvalidVolumeConfigError, "volume size '%d' cannot be greater than '%d'", intSize, max)
+			return newDiagnostic(InvalidVolumeConfigError, "volume size '%d' cannot be greater than '%d'", intSize, max).withField("size").withValue(vc.Size)
 		}
 	}
 
@@ -165,9 +166,9 @@ func (vds VolumeDefinitions) Contains(path string) bool {
 }
 
 func (vds VolumeDefinitions) validate(valCtx *ValidationContext) error {
-	for _, v := range vds {
+	for i, v := range vds {
 		if err := v.V2Validate(valCtx); err != nil {
-			return mask(err)
+			return mask(diagnosticInField(err, fmt.Sprintf("[%d]", i)))
 		}
 	}
 
@@ -177,9 +178,9 @@ func (vds VolumeDefinitions) validate(valCtx *ValidationContext) error {
 // validateVolumesRefs checks for each volume in each component the existance of reference names in the given volume config.
 func (nds *ComponentDefinitions) validateVolumesRefs() error {
 	for componentName, componentDef := range *nds {
-		for _, vc := range componentDef.Volumes {
+		for i, vc := range componentDef.Volumes {
 			if err := nds.validateVolumeRefs(vc, componentName); err != nil {
-				return mask(err)
+				return mask(diagnosticInField(diagnosticInComponent(err, componentName), fmt.Sprintf("volumes[%d]", i)))
 			}
 		}
 	}
@@ -188,9 +189,9 @@ func (nds *ComponentDefinitions) validateVolumesRefs() error {
 
 // validateVolumeRefs checks the existance of reference names in the given volume config.
 func (nds *ComponentDefinitions) validateVolumeRefs(vc VolumeConfig, containingComponentName ComponentName) error {
-	componentName := vc.VolumesFrom
+	componentName, field := vc.VolumesFrom, "volumes-from"
 	if componentName == "" {
-		componentName = vc.VolumeFrom
+		componentName, field = vc.VolumeFrom, "volume-from"
 	}
 	if componentName == "" {
 		// No references, all ok
@@ -199,13 +200,13 @@ func (nds *ComponentDefinitions) validateVolumeRefs(vc VolumeConfig, containingC
 
 	// Check that the component name (volume-from or volumes-from) is not the containing component
 	if componentName == containingComponentName.String() {
-		return maskf(InvalidVolumeConfigError, "cannot refer to own component '%s'", componentName)
+		return newDiagnostic(InvalidVolumeConfigError, "cannot refer to own component '%s'", componentName).withField(field).withValue(componentName)
 	}
 	// Another component is referenced, we should be in a pod
 	// Find the root of our pod
 	podRootName, _, err := nds.PodRoot(containingComponentName)
 	if err != nil {
-		return maskf(InvalidVolumeConfigError, "cannot refer to another component '%s' without a pod declaration", componentName)
+		return newDiagnostic(InvalidVolumeConfigError, "cannot refer to another component '%s' without a pod declaration", componentName).withField(field).withValue(componentName)
 	}
 	// Get the components that are part of the same pod
 	podComponents, err := nds.PodComponents(podRootName)
@@ -219,7 +220,7 @@ func (nds *ComponentDefinitions) validateVolumeRefs(vc VolumeConfig, containingC
 		// Check matching "volume-path"
 		if vc.VolumePath != "" {
 			if !other.Volumes.Contains(vc.VolumePath) {
-				return maskf(InvalidVolumeConfigError, "cannot find path '%s' on component '%s'", vc.VolumePath, componentName)
+				return newDiagnostic(InvalidVolumeConfigError, "cannot find path '%s' on component '%s'", vc.VolumePath, componentName).withField("volume-path").withValue(vc.VolumePath)
 			}
 		}
 		// all ok
@@ -229,10 +230,10 @@ func (nds *ComponentDefinitions) validateVolumeRefs(vc VolumeConfig, containingC
 	// Other component is not found in the same pod
 	// Does the other component even exists?
 	if _, err := nds.ComponentByName(ComponentName(componentName)); err == nil {
-		return maskf(InvalidVolumeConfigError, "cannot refer to another component '%s' that is not part of the same pod", componentName)
+		return newDiagnostic(InvalidVolumeConfigError, "cannot refer to another component '%s' that is not part of the same pod", componentName).withField(field).withValue(componentName)
 	} else {
 		// Other component not found
-		return maskf(InvalidVolumeConfigError, "cannot find referenced component '%s'", componentName)
+		return newDiagnostic(InvalidVolumeConfigError, "cannot find referenced component '%s'", componentName).withField(field).withValue(componentName)
 	}
 }
 
@@ -240,7 +241,8 @@ func (nds *ComponentDefinitions) validateVolumeRefs(vc VolumeConfig, containingC
 func (nds *ComponentDefinitions) validateUniqueMountPoints() error {
 	for componentName, componentDef := range *nds {
 		mountPoints := make(map[string]string)
-		for _, v := range componentDef.Volumes {
+		for i, v := range componentDef.Volumes {
+			field := fmt.Sprintf("volumes[%d]", i)
 			var paths []string
 			if v.Path != "" {
 				paths = []string{normalizeFolder(v.Path)}
@@ -248,7 +250,7 @@ func (nds *ComponentDefinitions) validateUniqueMountPoints() error {
 				paths = []string{normalizeFolder(v.VolumePath)}
 			} else if v.VolumesFrom != "" {
 				if _, err := nds.ComponentByName(ComponentName(v.VolumesFrom)); err != nil {
-					return maskf(InvalidVolumeConfigError, "cannot find referenced component '%s'", v.VolumesFrom)
+					return newDiagnostic(InvalidVolumeConfigError, "cannot find referenced component '%s'", v.VolumesFrom).withComponent(componentName).withField(field + ".volumes-from").withValue(v.VolumesFrom)
 				}
 				var err error
 				paths, err = nds.MountPoints(ComponentName(v.VolumesFrom))
@@ -256,12 +258,12 @@ func (nds *ComponentDefinitions) validateUniqueMountPoints() error {
 					return mask(err)
 				}
 			} else {
-				return maskf(InvalidVolumeConfigError, "missing path in component '%s'", componentName.String())
+				return newDiagnostic(InvalidVolumeConfigError, "missing path in component '%s'", componentName.String()).withComponent(componentName).withField(field)
 			}
 			for _, p := range paths {
 				if _, ok := mountPoints[p]; ok {
 					// Found duplicate mount point
-					return maskf(DuplicateVolumePathError, "duplicate volume '%s' found in component '%s'", p, componentName.String())
+					return newDiagnostic(DuplicateVolumePathError, "duplicate volume '%s' found in component '%s'", p, componentName.String()).withComponent(componentName).withField(field).withValue(p)
 				}
 				mountPoints[p] = p
 			}
@@ -271,3 +273,49 @@ func (nds *ComponentDefinitions) validateUniqueMountPoints() error {
 	// No duplicates detected
 	return nil
 }
+
+// validateMountPointConflicts checks that no file or secret file of a
+// component is placed on or inside one of its volumes, as it would be
+// hidden by the volume.
+func (nds *ComponentDefinitions) validateMountPointConflicts() error {
+	for _, name := range orderedComponentKeys(*nds) {
+		componentName := ComponentName(name)
+		componentDef := (*nds)[componentName]
+		if len(componentDef.Files) == 0 && len(componentDef.Secrets) == 0 {
+			continue
+		}
+
+		mountPoints, err := nds.MountPoints(componentName)
+		if err != nil {
+			return mask(err)
+		}
+
+		for i, f := range componentDef.Files {
+			if mp, ok := mountPointOf(f.Path, mountPoints); ok {
+				return newDiagnostic(InvalidFileDefinitionError, "file '%s' conflicts with volume '%s' in component '%s'", f.Path, mp, componentName.String()).withComponent(componentName).withField(fmt.Sprintf("files[%d].path", i)).withValue(f.Path)
+			}
+		}
+		for i, sd := range componentDef.Secrets {
+			if sd.Path == "" {
+				continue
+			}
+			if mp, ok := mountPointOf(sd.Path, mountPoints); ok {
+				return newDiagnostic(InvalidSecretDefinitionError, "path '%s' of secret '%s' conflicts with volume '%s' in component '%s'", sd.Path, sd.Name, mp, componentName.String()).withComponent(componentName).withField(fmt.Sprintf("secrets[%d].path", i)).withValue(sd.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// mountPointOf returns the first of the given mount points the given path is
+// on or inside of, if any.
+func mountPointOf(p string, mountPoints []string) (string, bool) {
+	p = normalizeFolder(p)
+	for _, mp := range mountPoints {
+		if p == mp || strings.HasPrefix(p, mp+"/") {
+			return mp, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,32 @@
+package userconfig
+
+import (
+	"fmt"
+)
+
+// Warning describes a part of a service definition that is valid, but likely
+// not what the user intended.
+type Warning struct {
+	// Component the warning is about.
+	Component ComponentName
+
+	// Human readable description of the warning.
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("component '%s': %s", w.Component, w.Message)
+}
+
+type Warnings []Warning
+
+// Warnings returns all warnings of this ServiceDefinition. The definition is
+// expected to be valid. Like Lint, warnings of rules a component switches off
+// through x-lint-ignore or its comment are not reported.
+func (sd *ServiceDefinition) Warnings() Warnings {
+	warnings := Warnings{}
+	warnings = append(warnings, sd.Components.notLintIgnored(LintRuleRouteOverlap, sd.Components.routeWarnings())...)
+	warnings = append(warnings, sd.Components.notLintIgnored(LintRuleLinkEnv, sd.Components.linkEnvWarnings())...)
+
+	return warnings
+}